@@ -1,23 +1,31 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
 var agentVersion = "dev"
 
+// statusClientClosedRequest reports a cancelled request, as the API does
+const statusClientClosedRequest = 499
+
 type runtimeState struct {
 	startedAt        time.Time
 	lastRequestError string
@@ -35,8 +43,15 @@ func main() {
 
 	gin.SetMode(ginMode)
 
-	client := httpclient.NewClient()
+	client := httpclient.NewClientWithOptions(httpclient.ClientOptions{
+		LocalFiles:         true,
+		SystemProxy:        true,
+		MaxResponseSize:    getEnvInt64("AGENT_RESPONSE_MAX_SIZE"),
+		ResponseBufferSize: getEnvInt64("AGENT_RESPONSE_BUFFER_SIZE"),
+	})
 	state := &runtimeState{startedAt: time.Now().UTC()}
+	executions := httpclient.NewExecutions()
+	bodies := httpclient.NewResponseBodies(15 * time.Minute)
 	router := gin.New()
 	router.Use(gin.Logger(), gin.Recovery())
 	if err := router.SetTrustedProxies(nil); err != nil {
@@ -115,13 +130,225 @@ func main() {
 			return
 		}
 
-		response, err := client.Execute(config)
+		ctx, executionID, done, ok := startExecution(c, executions, config)
+		if !ok {
+			return
+		}
+		defer done()
+
+		// The browser sends the non-secret scopes it knows about; same precedence as the server
+		resolver := httpclient.NewResolver(append(config.Scopes, config.RequestLayer())...)
+		response, err := client.Execute(ctx, resolver.Apply(config))
+		if errors.Is(err, context.Canceled) {
+			c.JSON(statusClientClosedRequest, gin.H{"error": "Request cancelled", "executionId": executionID})
+			return
+		}
+		if err != nil {
+			state.lastRequestError = err.Error()
+			state.lastErrorAt = time.Now().UTC().Format(time.RFC3339)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to execute request: " + err.Error()})
+			return
+		}
+		response.ExecutionID = executionID
+		if err := bodies.Keep("", response); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to keep response body: " + err.Error()})
+			return
+		}
+
+		state.lastRequestError = ""
+		state.lastErrorAt = ""
+
+		c.JSON(http.StatusOK, response)
+	})
+
+	// Full body of a truncated response, see RequestHandler.DownloadResponseBody
+	router.GET("/bodies/:id", func(c *gin.Context) {
+		if !hasValidAgentToken(c, agentToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+
+		body, err := bodies.Get("", c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		file, err := body.Open()
+		if errors.Is(err, httpclient.ErrResponseBodyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+
+		contentType := body.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		c.DataFromReader(http.StatusOK, body.Size, contentType, file, map[string]string{
+			"Content-Disposition": fmt.Sprintf("attachment; filename=%q", body.Filename()),
+		})
+	})
+
+	// Streams the response as Server-Sent Events, see RequestHandler.ExecuteStream.
+	// Closing the connection cancels the request; the browser saves the summary
+	// from the "done" event to history.
+	router.POST("/execute/stream", func(c *gin.Context) {
+		if !hasValidAgentToken(c, agentToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+
+		var config httpclient.RequestConfig
+		if err := c.ShouldBindJSON(&config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+			return
+		}
+
+		if strings.TrimSpace(config.URL) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+			return
+		}
+
+		ctx, executionID, done, ok := startExecution(c, executions, config)
+		if !ok {
+			return
+		}
+		defer done()
+
+		streaming := false
+		emit := func(message httpclient.StreamMessage) error {
+			if !streaming {
+				streaming = true
+				c.Header("Content-Type", "text/event-stream")
+				c.Header("Cache-Control", "no-cache")
+				c.Status(http.StatusOK)
+			}
+			c.SSEvent(message.Type, message)
+			c.Writer.Flush()
+			return c.Request.Context().Err()
+		}
+
+		resolver := httpclient.NewResolver(append(config.Scopes, config.RequestLayer())...)
+		result, err := client.ExecuteStream(ctx, resolver.Apply(config), emit)
+		if err != nil {
+			state.lastRequestError = err.Error()
+			state.lastErrorAt = time.Now().UTC().Format(time.RFC3339)
+			if !streaming {
+				c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to execute request: " + err.Error()})
+				return
+			}
+			c.SSEvent("error", gin.H{"error": err.Error()})
+			c.Writer.Flush()
+			return
+		}
+
+		result.ExecutionID = executionID
+
+		state.lastRequestError = ""
+		state.lastErrorAt = ""
+
+		c.SSEvent("done", result)
+		c.Writer.Flush()
+	})
+
+	// Schema of a GraphQL endpoint, see RequestHandler.IntrospectGraphQL
+	router.POST("/graphql/introspect", func(c *gin.Context) {
+		if !hasValidAgentToken(c, agentToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+
+		var config httpclient.RequestConfig
+		if err := c.ShouldBindJSON(&config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+			return
+		}
+
+		if strings.TrimSpace(config.URL) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+			return
+		}
+
+		resolver := httpclient.NewResolver(append(config.Scopes, config.RequestLayer())...)
+		schema, err := client.Introspect(c.Request.Context(), resolver.Apply(config), c.Query("refresh") == "true")
+		if err != nil {
+			state.lastRequestError = err.Error()
+			state.lastErrorAt = time.Now().UTC().Format(time.RFC3339)
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		schema.URL = config.URL
+
+		c.JSON(http.StatusOK, schema)
+	})
+
+	// gRPC discovery and calls, see RequestHandler.ListGRPCServices and
+	// RequestHandler.ExecuteGRPC; the browser saves calls to history
+	router.POST("/grpc/services", func(c *gin.Context) {
+		if !hasValidAgentToken(c, agentToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+
+		var config httpclient.RequestConfig
+		if err := c.ShouldBindJSON(&config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+			return
+		}
+
+		if strings.TrimSpace(config.URL) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+			return
+		}
+
+		resolver := httpclient.NewResolver(append(config.Scopes, config.RequestLayer())...)
+		services, err := client.GRPCServices(c.Request.Context(), resolver.Apply(config))
+		if err != nil {
+			state.lastRequestError = err.Error()
+			state.lastErrorAt = time.Now().UTC().Format(time.RFC3339)
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, services)
+	})
+
+	router.POST("/grpc/execute", func(c *gin.Context) {
+		if !hasValidAgentToken(c, agentToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+
+		var config httpclient.RequestConfig
+		if err := c.ShouldBindJSON(&config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+			return
+		}
+
+		if strings.TrimSpace(config.URL) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+			return
+		}
+
+		ctx, executionID, done, ok := startExecution(c, executions, config)
+		if !ok {
+			return
+		}
+		defer done()
+
+		resolver := httpclient.NewResolver(append(config.Scopes, config.RequestLayer())...)
+		response, err := client.ExecuteGRPC(ctx, resolver.Apply(config))
 		if err != nil {
 			state.lastRequestError = err.Error()
 			state.lastErrorAt = time.Now().UTC().Format(time.RFC3339)
 			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to execute request: " + err.Error()})
 			return
 		}
+		response.ExecutionID = executionID
 
 		state.lastRequestError = ""
 		state.lastErrorAt = ""
@@ -129,6 +356,94 @@ func main() {
 		c.JSON(http.StatusOK, response)
 	})
 
+	// Requests in flight, see RequestHandler.CancelExecution
+	router.GET("/executions", func(c *gin.Context) {
+		if !hasValidAgentToken(c, agentToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, executions.List(""))
+	})
+
+	router.POST("/executions/:id/cancel", func(c *gin.Context) {
+		if !hasValidAgentToken(c, agentToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+
+		if !executions.Cancel("", c.Param("id")) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Execution not found or already finished"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Execution cancelled"})
+	})
+
+	// Browsers cannot set headers on a WebSocket, so the token comes with the
+	// config in the first message, keeping it out of URLs and logs:
+	// {"action":"connect","token":"...","config":{...}}. Frames are then relayed
+	// as with RequestHandler.ConnectWebSocket; the browser saves the transcript
+	// from the "closed" event to history.
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			// Any page could open a WebSocket to localhost, so only browsers
+			// that name an allowed origin get one
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return false
+			}
+			if len(allowedOrigins) == 0 {
+				return true
+			}
+			for _, allowed := range allowedOrigins {
+				if origin == allowed {
+					return true
+				}
+			}
+			return false
+		},
+	}
+	router.GET("/ws", func(c *gin.Context) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+
+		var connect struct {
+			Action string                   `json:"action"`
+			Token  string                   `json:"token"`
+			Config httpclient.RequestConfig `json:"config"`
+		}
+		conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+		if err := conn.ReadJSON(&connect); err != nil || connect.Action != "connect" {
+			conn.WriteJSON(httpclient.WebSocketEvent{Event: "error", Error: "Expected a connect message with a URL"})
+			conn.Close()
+			return
+		}
+		if !validAgentToken(connect.Token, agentToken) {
+			conn.WriteJSON(httpclient.WebSocketEvent{Event: "error", Error: "Invalid agent token"})
+			conn.Close()
+			return
+		}
+		if strings.TrimSpace(connect.Config.URL) == "" {
+			conn.WriteJSON(httpclient.WebSocketEvent{Event: "error", Error: "Expected a connect message with a URL"})
+			conn.Close()
+			return
+		}
+		conn.SetReadDeadline(time.Time{})
+
+		resolver := httpclient.NewResolver(append(connect.Config.Scopes, connect.Config.RequestLayer())...)
+		if _, err := client.RelayWebSocket(c.Request.Context(), resolver.Apply(connect.Config), conn); err != nil {
+			state.lastRequestError = err.Error()
+			state.lastErrorAt = time.Now().UTC().Format(time.RFC3339)
+			return
+		}
+
+		state.lastRequestError = ""
+		state.lastErrorAt = ""
+	})
+
 	log.Printf("Local agent starting on http://127.0.0.1:%s", port)
 	if len(allowedOrigins) == 0 {
 		log.Println("Allowed origins: * (all origins enabled)")
@@ -154,6 +469,20 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt64 returns a positive integer setting, or zero for the default
+func getEnvInt64(key string) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed <= 0 {
+		log.Printf("Invalid %s %q, using the default", key, value)
+		return 0
+	}
+	return parsed
+}
+
 func getEnvSlice(key string) []string {
 	if value := os.Getenv(key); value != "" {
 		parts := strings.Split(value, ",")
@@ -222,11 +551,31 @@ func resolveAgentToken() (string, string, error) {
 	return generatedToken, tokenFile, nil
 }
 
+// startExecution tracks a request so it can be cancelled, responding with an
+// error when its execution ID is invalid or already in flight
+func startExecution(c *gin.Context, executions *httpclient.Executions, config httpclient.RequestConfig) (context.Context, string, func(), bool) {
+	ctx, executionID, done, err := executions.Start(c.Request.Context(), "", config)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, httpclient.ErrExecutionIDInUse) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return nil, "", nil, false
+	}
+	return ctx, executionID, done, true
+}
+
 func hasValidAgentToken(c *gin.Context, expected string) bool {
-	token := strings.TrimSpace(c.GetHeader("X-APEYE-Agent-Token"))
+	return validAgentToken(c.GetHeader("X-APEYE-Agent-Token"), expected)
+}
+
+// validAgentToken compares tokens in constant time
+func validAgentToken(token, expected string) bool {
+	token = strings.TrimSpace(token)
 	if token == "" {
 		return false
 	}
 
-	return token == expected
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"log"
 
 	"github.com/Akash-YS05/apeye-app/apeye-backend/config"
@@ -10,6 +11,7 @@ import (
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/routes"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/database"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/secrets"
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,6 +28,10 @@ func main() {
 	}
 	defer database.Close()
 
+	// Connect to Redis, which holds rate limit counters
+	database.ConnectRedis(&cfg.Redis)
+	defer database.CloseRedis()
+
 	// Auto-migrate
 	if err := database.AutoMigrate(); err != nil {
 		log.Fatal("Failed to run migrations:", err)
@@ -37,17 +43,51 @@ func main() {
 	workspaceRepo := repository.NewWorkspaceRepository(database.GetDB())
 	requestRepo := repository.NewRequestRepository(database.GetDB())
 	environmentRepo := repository.NewEnvironmentRepository(database.GetDB())
+	memberRepo := repository.NewWorkspaceMemberRepository(database.GetDB())
+	invitationRepo := repository.NewWorkspaceInvitationRepository(database.GetDB())
+	userRepo := repository.NewUserRepository(database.GetDB())
+	auditRepo := repository.NewAuditRepository(database.GetDB())
+	revisionRepo := repository.NewRevisionRepository(database.GetDB())
+	variableSetRepo := repository.NewVariableSetRepository(database.GetDB())
+	certificateRepo := repository.NewCertificateRepository(database.GetDB())
+	proxyRepo := repository.NewProxyRepository(database.GetDB())
+	cookieRepo := repository.NewCookieRepository(database.GetDB())
+	blobRepo := repository.NewBlobRepository(database.GetDB())
+
+	// Initialize secrets keyring
+	keyring, err := secrets.NewKeyring(cfg.Secrets.MasterKey)
+	if errors.Is(err, secrets.ErrMissingMasterKey) {
+		log.Fatal("SECRETS_MASTER_KEY must be set when GIN_MODE=release")
+	}
+	if err != nil {
+		log.Fatal("Failed to initialize secrets keyring:", err)
+	}
 
 	// Initialize services
-	requestService := services.NewRequestService(historyRepo)
-	collectionService := services.NewCollectionService(collectionRepo, workspaceRepo, requestRepo)
-	environmentService := services.NewEnvironmentService(environmentRepo, workspaceRepo)
+	workspaceService := services.NewWorkspaceService(workspaceRepo, memberRepo, invitationRepo, userRepo)
+	auditService := services.NewAuditService(auditRepo, workspaceService)
+	revisionService := services.NewRevisionService(revisionRepo)
+	collectionService := services.NewCollectionService(collectionRepo, workspaceService, requestRepo, auditService, revisionService)
+	environmentService := services.NewEnvironmentService(environmentRepo, workspaceService, auditService, keyring)
+	variableService := services.NewVariableService(variableSetRepo, workspaceService, collectionService, environmentService, keyring)
+	certificateService := services.NewCertificateService(certificateRepo, keyring)
+	proxyService := services.NewProxyService(proxyRepo, workspaceService, keyring)
+	cookieService := services.NewCookieService(cookieRepo, workspaceService)
+	blobService := services.NewBlobService(blobRepo, workspaceService, cfg.Blobs)
+	requestService := services.NewRequestService(historyRepo, variableService, certificateService, proxyService, cookieService, blobService, cfg.Response, cfg.Egress)
 
 	// Initialize handlers
 	requestHandler := handlers.NewRequestHandler(requestService)
 	collectionHandler := handlers.NewCollectionHandler(collectionService)
 	historyHandler := handlers.NewHistoryHandler(historyRepo)
 	environmentHandler := handlers.NewEnvironmentHandler(environmentService)
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	variableHandler := handlers.NewVariableHandler(variableService)
+	certificateHandler := handlers.NewCertificateHandler(certificateService)
+	proxyHandler := handlers.NewProxyHandler(proxyService)
+	cookieHandler := handlers.NewCookieHandler(cookieService)
+	blobHandler := handlers.NewBlobHandler(blobService)
 
 	// Initialize router
 	router := gin.Default()
@@ -56,7 +96,7 @@ func main() {
 	router.Use(middleware.CORSMiddleware(cfg))
 
 	// Setup routes
-	routes.SetupRoutes(router, cfg, requestHandler, collectionHandler, historyHandler, environmentHandler)
+	routes.SetupRoutes(router, cfg, requestHandler, collectionHandler, historyHandler, environmentHandler, workspaceHandler, auditHandler, variableHandler, certificateHandler, proxyHandler, cookieHandler, blobHandler)
 
 	// Start server
 	log.Printf("🚀 Server starting on port %s", cfg.Server.Port)
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +17,10 @@ type Config struct {
 	JWT       JWTConfig
 	CORS      CORSConfig
 	RateLimit RateLimitConfig
+	Secrets   SecretsConfig
+	Response  ResponseConfig
+	Blobs     BlobConfig
+	Egress    EgressConfig
 }
 
 type ServerConfig struct {
@@ -50,8 +55,41 @@ type CORSConfig struct {
 }
 
 type RateLimitConfig struct {
-	Free int
-	Pro  int
+	// Free and Pro are the requests each plan may execute per Window
+	Free   int
+	Pro    int
+	Window time.Duration
+}
+
+type SecretsConfig struct {
+	// MasterKey wraps the per-environment keys used to encrypt secret variables.
+	// Either a base64 encoded 32 byte key or a passphrase.
+	MasterKey string
+}
+
+type ResponseConfig struct {
+	// MaxSize fails executions whose response body is larger. Bodies beyond
+	// BufferSize are written to a temp file in TempDir, returned as a
+	// truncated preview and kept for download for BodyTTL.
+	MaxSize    int64
+	BufferSize int64
+	TempDir    string
+	BodyTTL    time.Duration
+}
+
+type BlobConfig struct {
+	// Dir holds the files uploaded for binary request bodies
+	Dir     string
+	MaxSize int64
+}
+
+type EgressConfig struct {
+	// DenyPrivate stops the server executor from reaching loopback, private
+	// and link-local addresses such as cloud metadata endpoints. AllowHosts
+	// and DenyHosts take host names, ".domain" suffixes, globs or CIDR ranges.
+	DenyPrivate bool
+	AllowHosts  []string
+	DenyHosts   []string
 }
 
 func Load() *Config {
@@ -59,10 +97,12 @@ func Load() *Config {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	ginMode := getEnv("GIN_MODE", "debug")
+
 	return &Config{
 		Server: ServerConfig{
 			Port:    getEnv("PORT", "8080"),
-			GinMode: getEnv("GIN_MODE", "debug"),
+			GinMode: ginMode,
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -87,12 +127,48 @@ func Load() *Config {
 			AllowedOrigins: getEnvSlice("ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
 		},
 		RateLimit: RateLimitConfig{
-			Free: 100,
-			Pro:  10000,
+			Free:   int(getEnvInt64("RATE_LIMIT_FREE", 100)),
+			Pro:    int(getEnvInt64("RATE_LIMIT_PRO", 10000)),
+			Window: getEnvDuration("RATE_LIMIT_WINDOW", time.Hour),
+		},
+		Secrets: SecretsConfig{
+			MasterKey: secretsMasterKey(ginMode),
+		},
+		Response: ResponseConfig{
+			MaxSize:    getEnvInt64("RESPONSE_MAX_SIZE", 100<<20),
+			BufferSize: getEnvInt64("RESPONSE_BUFFER_SIZE", 5<<20),
+			TempDir:    getEnv("RESPONSE_TEMP_DIR", ""),
+			BodyTTL:    15 * time.Minute,
+		},
+		Blobs: BlobConfig{
+			Dir:     getEnv("BLOB_DIR", "data/blobs"),
+			MaxSize: getEnvInt64("BLOB_MAX_SIZE", 100<<20),
+		},
+		Egress: EgressConfig{
+			DenyPrivate: getEnvBool("EGRESS_DENY_PRIVATE", true),
+			AllowHosts:  getEnvSlice("EGRESS_ALLOW_HOSTS", nil),
+			DenyHosts:   getEnvSlice("EGRESS_DENY_HOSTS", nil),
 		},
 	}
 }
 
+// developmentMasterKey encrypts secrets in local setups without
+// SECRETS_MASTER_KEY. It is public, so release mode never uses it.
+const developmentMasterKey = "apeye-insecure-development-master-key"
+
+// secretsMasterKey reads SECRETS_MASTER_KEY. In release mode it has no
+// default, so the server refuses to start without one.
+func secretsMasterKey(ginMode string) string {
+	if key := getEnv("SECRETS_MASTER_KEY", ""); key != "" {
+		return key
+	}
+	if ginMode == "release" {
+		return ""
+	}
+	log.Println("⚠️  SECRETS_MASTER_KEY is not set: secrets are encrypted with a publicly known development key. Never run like this in production.")
+	return developmentMasterKey
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -100,6 +176,36 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid %s %q, using %t", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
 func getEnvSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		return strings.Split(value, ",")
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type BlobHandler struct {
+	blobService *services.BlobService
+}
+
+func NewBlobHandler(blobService *services.BlobService) *BlobHandler {
+	return &BlobHandler{
+		blobService: blobService,
+	}
+}
+
+// ListBlobs returns the files uploaded to a workspace
+func (h *BlobHandler) ListBlobs(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	blobs, err := h.blobService.ListBlobs(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, blobs)
+}
+
+// UploadBlob stores the multipart file "file" for binary request bodies. The
+// part is streamed to storage rather than parsed into memory.
+func (h *BlobHandler) UploadBlob(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected a multipart upload with a \"file\" part"})
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid upload: " + err.Error()})
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		blob, err := h.blobService.UploadBlob(userID, c.Param("id"), part.FileName(), part.Header.Get("Content-Type"), part)
+		part.Close()
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, blob)
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Expected a multipart upload with a \"file\" part"})
+}
+
+// DeleteBlob removes an uploaded file
+func (h *BlobHandler) DeleteBlob(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.blobService.DeleteBlob(userID, c.Param("id"), c.Param("blobId")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "File deleted"})
+}
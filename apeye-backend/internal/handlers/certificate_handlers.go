@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxCertificateFileSize limits each uploaded PEM file
+const maxCertificateFileSize = 1 << 20
+
+type CertificateHandler struct {
+	certificateService *services.CertificateService
+}
+
+func NewCertificateHandler(certificateService *services.CertificateService) *CertificateHandler {
+	return &CertificateHandler{
+		certificateService: certificateService,
+	}
+}
+
+// ListCertificates returns the current user's client certificates
+func (h *CertificateHandler) ListCertificates(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	certs, err := h.certificateService.ListCertificates(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, certs)
+}
+
+// CreateCertificate uploads a client certificate/key pair and/or CA bundle,
+// either as JSON or as multipart files "certificate", "privateKey" and "ca"
+func (h *CertificateHandler) CreateCertificate(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.CreateCertificateInput
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		input.Name = c.PostForm("name")
+		input.HostPattern = c.PostForm("hostPattern")
+		for field, value := range map[string]*string{
+			"certificate": &input.Certificate,
+			"privateKey":  &input.PrivateKey,
+			"ca":          &input.CA,
+		} {
+			header, err := c.FormFile(field)
+			if err != nil {
+				continue
+			}
+			data, err := readFormFile(header)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			*value = data
+		}
+	} else if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cert, err := h.certificateService.CreateCertificate(userID, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cert)
+}
+
+// DeleteCertificate deletes a client certificate
+func (h *CertificateHandler) DeleteCertificate(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.certificateService.DeleteCertificate(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate deleted"})
+}
+
+// readFormFile reads an uploaded PEM file
+func readFormFile(header *multipart.FileHeader) (string, error) {
+	if header.Size > maxCertificateFileSize {
+		return "", fmt.Errorf("%s is too large", header.Filename)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxCertificateFileSize))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
@@ -28,8 +29,12 @@ func (h *CollectionHandler) ListCollections(c *gin.Context) {
 		return
 	}
 
-	collections, err := h.collectionService.GetUserCollections(userID)
+	collections, err := h.collectionService.GetUserCollections(userID, c.Query("workspace_id"))
 	if err != nil {
+		if isAccessError(err) {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
 		return
 	}
@@ -59,7 +64,7 @@ func (h *CollectionHandler) CreateCollection(c *gin.Context) {
 	collection, err := h.collectionService.CreateCollection(userID, input)
 	if err != nil {
 		log.Println("CreateCollection service error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -82,7 +87,7 @@ func (h *CollectionHandler) GetCollection(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -106,7 +111,7 @@ func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
 
 	collection, err := h.collectionService.UpdateCollection(userID, collectionID, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -123,7 +128,7 @@ func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
 
 	collectionID := c.Param("id")
 	if err := h.collectionService.DeleteCollection(userID, collectionID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -148,7 +153,7 @@ func (h *CollectionHandler) SaveRequest(c *gin.Context) {
 
 	request, err := h.collectionService.SaveRequest(userID, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -165,9 +170,219 @@ func (h *CollectionHandler) DeleteRequest(c *gin.Context) {
 
 	requestID := c.Param("id")
 	if err := h.collectionService.DeleteRequest(userID, requestID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Request deleted"})
-}
\ No newline at end of file
+}
+// UpdateRequest updates a saved request
+func (h *CollectionHandler) UpdateRequest(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.UpdateRequestInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := h.collectionService.UpdateRequest(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ListCollectionRevisions returns the version history of a collection
+func (h *CollectionHandler) ListCollectionRevisions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	revisions, err := h.collectionService.ListCollectionRevisions(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetCollectionRevision returns a single version of a collection
+func (h *CollectionHandler) GetCollectionRevision(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision version"})
+		return
+	}
+
+	revision, err := h.collectionService.GetCollectionRevision(userID, c.Param("id"), version)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffCollectionRevisions compares two versions of a collection (?from=&to=)
+func (h *CollectionHandler) DiffCollectionRevisions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	from, to, ok := parseRevisionRange(c)
+	if !ok {
+		return
+	}
+
+	diff, err := h.collectionService.DiffCollectionRevisions(userID, c.Param("id"), from, to)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreCollectionRevision restores a collection to an older version
+func (h *CollectionHandler) RestoreCollectionRevision(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision version"})
+		return
+	}
+
+	collection, err := h.collectionService.RestoreCollectionRevision(userID, c.Param("id"), version)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// ListRequestRevisions returns the version history of a saved request
+func (h *CollectionHandler) ListRequestRevisions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	revisions, err := h.collectionService.ListRequestRevisions(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRequestRevision returns a single version of a saved request
+func (h *CollectionHandler) GetRequestRevision(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision version"})
+		return
+	}
+
+	revision, err := h.collectionService.GetRequestRevision(userID, c.Param("id"), version)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRequestRevisions compares two versions of a saved request (?from=&to=)
+func (h *CollectionHandler) DiffRequestRevisions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	from, to, ok := parseRevisionRange(c)
+	if !ok {
+		return
+	}
+
+	diff, err := h.collectionService.DiffRequestRevisions(userID, c.Param("id"), from, to)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreRequestRevision restores a saved request to an older version
+func (h *CollectionHandler) RestoreRequestRevision(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision version"})
+		return
+	}
+
+	request, err := h.collectionService.RestoreRequestRevision(userID, c.Param("id"), version)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// parseRevisionRange reads the optional from/to versions of a diff request.
+// It writes a 400 response and returns false when either is malformed.
+func parseRevisionRange(c *gin.Context) (int, int, bool) {
+	from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+	if err != nil || from < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from version"})
+		return 0, 0, false
+	}
+
+	to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if err != nil || to < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to version"})
+		return 0, 0, false
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CookieHandler struct {
+	cookieService *services.CookieService
+}
+
+func NewCookieHandler(cookieService *services.CookieService) *CookieHandler {
+	return &CookieHandler{
+		cookieService: cookieService,
+	}
+}
+
+// ListCookies returns a workspace's cookie jar, optionally filtered with ?domain=
+func (h *CookieHandler) ListCookies(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	cookies, err := h.cookieService.ListCookies(userID, c.Param("id"), c.Query("domain"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cookies)
+}
+
+// SetCookie adds or replaces a cookie in a workspace's jar
+func (h *CookieHandler) SetCookie(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.CookieInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cookie, err := h.cookieService.SetCookie(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cookie)
+}
+
+// UpdateCookie edits a cookie in a workspace's jar
+func (h *CookieHandler) UpdateCookie(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.CookieInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cookie, err := h.cookieService.UpdateCookie(userID, c.Param("id"), c.Param("cookieId"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cookie)
+}
+
+// DeleteCookie removes a cookie from a workspace's jar
+func (h *CookieHandler) DeleteCookie(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.cookieService.DeleteCookie(userID, c.Param("id"), c.Param("cookieId")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cookie deleted"})
+}
+
+// ClearCookies empties a workspace's jar, or one domain with ?domain=
+func (h *CookieHandler) ClearCookies(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.cookieService.ClearCookies(userID, c.Param("id"), c.Query("domain")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cookies cleared"})
+}
@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"unicode"
 
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
@@ -26,8 +30,12 @@ func (h *EnvironmentHandler) ListEnvironments(c *gin.Context) {
 		return
 	}
 
-	environments, err := h.environmentService.GetUserEnvironments(userID)
+	environments, err := h.environmentService.GetUserEnvironments(userID, c.Query("workspace_id"))
 	if err != nil {
+		if isAccessError(err) {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch environments"})
 		return
 	}
@@ -51,7 +59,7 @@ func (h *EnvironmentHandler) CreateEnvironment(c *gin.Context) {
 
 	environment, err := h.environmentService.CreateEnvironment(userID, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -73,7 +81,7 @@ func (h *EnvironmentHandler) GetEnvironment(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Environment not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -97,7 +105,7 @@ func (h *EnvironmentHandler) UpdateEnvironment(c *gin.Context) {
 
 	environment, err := h.environmentService.UpdateEnvironment(userID, environmentID, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -114,9 +122,153 @@ func (h *EnvironmentHandler) DeleteEnvironment(c *gin.Context) {
 
 	environmentID := c.Param("id")
 	if err := h.environmentService.DeleteEnvironment(userID, environmentID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Environment deleted"})
 }
+
+// maxEnvironmentFileSize limits uploaded environment files
+const maxEnvironmentFileSize = 1 << 20
+
+// CloneEnvironment copies an environment
+func (h *EnvironmentHandler) CloneEnvironment(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// The body is optional
+	var input services.CloneEnvironmentInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	environment, err := h.environmentService.CloneEnvironment(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, environment)
+}
+
+// DiffEnvironments compares an environment with the one given in ?with=
+func (h *EnvironmentHandler) DiffEnvironments(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	other := c.Query("with")
+	if other == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "with is required"})
+		return
+	}
+
+	diff, err := h.environmentService.DiffEnvironments(userID, c.Param("id"), other)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// ImportEnvironment loads variables from an uploaded dotenv or JSON file.
+// The file is sent as multipart "file" or as the raw request body.
+func (h *EnvironmentHandler) ImportEnvironment(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	input := services.ImportEnvironmentInput{
+		Format:  c.Query("format"),
+		Replace: c.Query("mode") == "replace",
+	}
+
+	var reader io.Reader
+	filename := ""
+	if file, header, err := c.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		reader = file
+		filename = header.Filename
+	} else {
+		reader = c.Request.Body
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxEnvironmentFileSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	if len(data) > maxEnvironmentFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+		return
+	}
+	input.Data = data
+
+	if input.Format == "" {
+		if strings.HasSuffix(strings.ToLower(filename), ".json") || strings.Contains(c.ContentType(), "json") {
+			input.Format = services.EnvironmentFormatJSON
+		} else {
+			input.Format = services.EnvironmentFormatDotenv
+		}
+	}
+
+	environment, err := h.environmentService.ImportEnvironment(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, environment)
+}
+
+// ExportEnvironment downloads an environment as a dotenv or JSON file
+func (h *EnvironmentHandler) ExportEnvironment(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	format := c.DefaultQuery("format", services.EnvironmentFormatDotenv)
+	includeSecrets := c.Query("include_secrets") == "true"
+
+	environment, data, err := h.environmentService.ExportEnvironment(userID, c.Param("id"), format, includeSecrets)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType, extension := "text/plain; charset=utf-8", ".env"
+	if format == services.EnvironmentFormatJSON {
+		contentType, extension = "application/json", ".json"
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s%s"`, exportFilename(environment.Name), extension))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// exportFilename turns an environment name into a safe file name
+func exportFilename(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, strings.TrimSpace(name))
+	if safe == "" {
+		return "environment"
+	}
+	return safe
+}
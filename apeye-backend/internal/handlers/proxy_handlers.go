@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ProxyHandler struct {
+	proxyService *services.ProxyService
+}
+
+func NewProxyHandler(proxyService *services.ProxyService) *ProxyHandler {
+	return &ProxyHandler{
+		proxyService: proxyService,
+	}
+}
+
+// GetWorkspaceProxy returns the proxy of a workspace
+func (h *ProxyHandler) GetWorkspaceProxy(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	setting, err := h.proxyService.GetWorkspaceProxy(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// UpdateWorkspaceProxy sets the proxy of a workspace
+func (h *ProxyHandler) UpdateWorkspaceProxy(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.UpdateProxyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setting, err := h.proxyService.UpdateWorkspaceProxy(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// DeleteWorkspaceProxy removes the proxy of a workspace
+func (h *ProxyHandler) DeleteWorkspaceProxy(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.proxyService.DeleteWorkspaceProxy(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Proxy removed"})
+}
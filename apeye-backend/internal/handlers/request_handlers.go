@@ -1,16 +1,31 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"strings"
 
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
+// statusClientClosedRequest reports a request cancelled before it completed,
+// the non-standard code nginx uses for the same case
+const statusClientClosedRequest = 499
+
+// webSocketUpgrader accepts any origin; the CORS middleware has already
+// rejected requests from origins that are not allowed.
+var webSocketUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
 type RequestHandler struct {
 	requestService *services.RequestService
 }
@@ -46,24 +61,348 @@ func (h *RequestHandler) ExecuteRequest(c *gin.Context) {
 		return
 	}
 
-	// Check for unresolved variables in URL (common error)
-	if strings.Contains(config.URL, "{{") {
+	log.Printf("Executing request: %s %s", config.Method, config.URL)
+
+	// Execute request
+	response, err := h.requestService.ExecuteRequest(c.Request.Context(), userID, config)
+	if err != nil {
+		respondExecuteError(c, config, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadResponseBody streams the full body of a truncated response. The
+// execute response carries its bodyId when the body was too large to inline.
+func (h *RequestHandler) DownloadResponseBody(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	body, err := h.requestService.GetResponseBody(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	file, err := body.Open()
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	contentType := body.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, body.Size, contentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", body.Filename()),
+	})
+}
+
+// ListExecutions returns the user's requests in flight
+func (h *RequestHandler) ListExecutions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.requestService.ListExecutions(userID))
+}
+
+// CancelExecution aborts a request in flight. The browser picks the execution
+// ID and sends it as executionId with the request, so it can cancel before the
+// response arrives.
+func (h *RequestHandler) CancelExecution(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.requestService.CancelExecution(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Execution cancelled"})
+}
+
+// ExecuteStream executes a request and forwards the response to the browser
+// as Server-Sent Events while it arrives: a "response" event with status and
+// headers, then "chunk" or "event" messages, then "done" with the summary
+// (or "error"). Closing the connection cancels the upstream request.
+func (h *RequestHandler) ExecuteStream(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var config httpclient.RequestConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+		return
+	}
+	if config.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+		return
+	}
+
+	// Errors before the upstream response arrives are reported as plain JSON
+	streaming := false
+	emit := func(message httpclient.StreamMessage) error {
+		if !streaming {
+			streaming = true
+			startEventStream(c)
+		}
+		c.SSEvent(message.Type, message)
+		c.Writer.Flush()
+		return c.Request.Context().Err()
+	}
+
+	result, err := h.requestService.ExecuteStream(c.Request.Context(), userID, config, emit)
+	if err != nil {
+		if !streaming {
+			respondExecuteError(c, config, err)
+			return
+		}
+		c.SSEvent("error", gin.H{"error": err.Error()})
+		c.Writer.Flush()
+		return
+	}
+
+	c.SSEvent("done", result)
+	c.Writer.Flush()
+}
+
+// IntrospectGraphQL returns the schema of a GraphQL endpoint for autocomplete,
+// sending the headers and auth of the request config. Pass ?refresh=true to
+// bypass the schema cache.
+func (h *RequestHandler) IntrospectGraphQL(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var config httpclient.RequestConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+		return
+	}
+	if config.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+		return
+	}
+
+	schema, err := h.requestService.IntrospectGraphQL(c.Request.Context(), userID, config, c.Query("refresh") == "true")
+	if err != nil {
+		respondExecuteError(c, config, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// ListGRPCServices lists the services and methods of a gRPC target, with an
+// example request message for each method
+func (h *RequestHandler) ListGRPCServices(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var config httpclient.RequestConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+		return
+	}
+	if config.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+		return
+	}
+
+	grpcServices, err := h.requestService.ListGRPCServices(c.Request.Context(), userID, config)
+	if err != nil {
+		respondExecuteError(c, config, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grpcServices)
+}
+
+// ExecuteGRPC calls the gRPC method in config.grpc with the JSON message in
+// the body. gRPC errors are reported in the response status, not as HTTP
+// errors.
+func (h *RequestHandler) ExecuteGRPC(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var config httpclient.RequestConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+		return
+	}
+	if config.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+		return
+	}
+
+	response, err := h.requestService.ExecuteGRPC(c.Request.Context(), userID, config)
+	if err != nil {
+		respondExecuteError(c, config, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateWebSocketSession prepares a WebSocket connection from a request config.
+// The browser then opens the returned path, which relays frames to and from
+// the target.
+func (h *RequestHandler) CreateWebSocketSession(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var config httpclient.RequestConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+		return
+	}
+	if config.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
+		return
+	}
+
+	id, err := h.requestService.CreateWebSocketSession(userID, config)
+	if err != nil {
+		respondExecuteError(c, config, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":   id,
+		"path": "/api/websocket/sessions/" + id,
+	})
+}
+
+// ConnectWebSocket upgrades the browser connection for a session and relays
+// frames until either side closes. The session ID authorizes the connection.
+func (h *RequestHandler) ConnectWebSocket(c *gin.Context) {
+	id := c.Param("id")
+	if !h.requestService.HasWebSocketSession(id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrWebSocketSessionNotFound.Error()})
+		return
+	}
+
+	conn, err := webSocketUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		return
+	}
+
+	if _, err := h.requestService.RelayWebSocket(c.Request.Context(), id, conn); err != nil {
+		log.Printf("WebSocket session %s failed: %v", id, err)
+	}
+}
+
+// startEventStream writes the headers of a Server-Sent Events response
+func startEventStream(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+}
+
+// respondExecuteError maps a request execution error to a response
+func respondExecuteError(c *gin.Context, config httpclient.RequestConfig, err error) {
+	if errors.Is(err, services.ErrUnresolvedVariables) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "URL contains unresolved variables. Please select an environment with the required variables defined.",
+			"error": err.Error(),
 			"url":   config.URL,
 		})
 		return
 	}
+	if errors.Is(err, context.Canceled) {
+		c.JSON(statusClientClosedRequest, gin.H{
+			"error":       "Request cancelled",
+			"executionId": config.ExecutionID,
+		})
+		return
+	}
+	if errors.Is(err, httpclient.ErrResponseTooLarge) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	var denied *httpclient.EgressError
+	if errors.As(err, &denied) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":    denied.Error(),
+			"host":     denied.Host,
+			"useAgent": true,
+		})
+		return
+	}
+	if isAccessError(err) ||
+		errors.Is(err, services.ErrEnvironmentNotFound) ||
+		errors.Is(err, services.ErrCollectionNotFound) ||
+		errors.Is(err, services.ErrBlobNotFound) ||
+		errors.Is(err, httpclient.ErrLocalFileUnsupported) ||
+		errors.Is(err, httpclient.ErrInvalidExecutionID) ||
+		errors.Is(err, httpclient.ErrExecutionIDInUse) {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Request execution failed: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute request: " + err.Error()})
+}
+
+// StartOAuth2Authorization prepares the OAuth2 authorization code flow with
+// PKCE. The client opens the returned URL, checks the state on the redirect and
+// then executes requests with the code and code verifier in auth.oauth2.
+func (h *RequestHandler) StartOAuth2Authorization(c *gin.Context) {
+	var cfg httpclient.OAuth2Config
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	log.Printf("Executing request: %s %s", config.Method, config.URL)
+	pkce, err := httpclient.NewPKCE()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PKCE verifier"})
+		return
+	}
 
-	// Execute request
-	response, err := h.requestService.ExecuteRequest(userID, config)
+	state := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate state"})
+		return
+	}
+
+	authorizationURL, err := httpclient.AuthorizationURL(&cfg, hex.EncodeToString(state), pkce)
 	if err != nil {
-		log.Printf("Request execution failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute request: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, gin.H{
+		"authorizationUrl":    authorizationURL,
+		"state":               hex.EncodeToString(state),
+		"codeVerifier":        pkce.Verifier,
+		"codeChallenge":       pkce.Challenge,
+		"codeChallengeMethod": pkce.Method,
+	})
 }
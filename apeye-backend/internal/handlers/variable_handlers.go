@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
+	"github.com/gin-gonic/gin"
+)
+
+type VariableHandler struct {
+	variableService *services.VariableService
+}
+
+func NewVariableHandler(variableService *services.VariableService) *VariableHandler {
+	return &VariableHandler{
+		variableService: variableService,
+	}
+}
+
+// GetGlobalVariables returns the current user's global variables
+func (h *VariableHandler) GetGlobalVariables(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	set, err := h.variableService.GetGlobalVariables(userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+// UpdateGlobalVariables replaces the current user's global variables
+func (h *VariableHandler) UpdateGlobalVariables(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.UpdateVariablesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := h.variableService.UpdateGlobalVariables(userID, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+// GetWorkspaceVariables returns the globals of a workspace
+func (h *VariableHandler) GetWorkspaceVariables(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	set, err := h.variableService.GetWorkspaceVariables(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+// UpdateWorkspaceVariables replaces the globals of a workspace
+func (h *VariableHandler) UpdateWorkspaceVariables(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.UpdateVariablesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := h.variableService.UpdateWorkspaceVariables(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+// GetCollectionVariables returns the variables of a collection
+func (h *VariableHandler) GetCollectionVariables(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	set, err := h.variableService.GetCollectionVariables(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+// UpdateCollectionVariables replaces the variables of a collection
+func (h *VariableHandler) UpdateCollectionVariables(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.UpdateVariablesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := h.variableService.UpdateCollectionVariables(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+// PreviewVariables shows how each placeholder of a request resolves and which scope it comes from
+func (h *VariableHandler) PreviewVariables(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var config httpclient.RequestConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request configuration: " + err.Error()})
+		return
+	}
+
+	preview, err := h.variableService.PreviewVariables(userID, config)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variables": preview})
+}
@@ -1,21 +1,75 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/middleware"
-	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
 	"github.com/gin-gonic/gin"
 )
 
 type WorkspaceHandler struct {
-	workspaceRepo *repository.WorkspaceRepository
+	workspaceService *services.WorkspaceService
 }
 
-func NewWorkspaceHandler(workspaceRepo *repository.WorkspaceRepository) *WorkspaceHandler {
-	return &WorkspaceHandler{workspaceRepo: workspaceRepo}
+func NewWorkspaceHandler(workspaceService *services.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService}
 }
 
+// isAccessError reports whether err comes from workspace membership checks
+func isAccessError(err error) bool {
+	return errors.Is(err, services.ErrUnauthorized) ||
+		errors.Is(err, services.ErrInsufficientRole) ||
+		errors.Is(err, services.ErrWorkspaceNotFound)
+}
+
+// errorStatus maps service errors to HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnauthorized),
+		errors.Is(err, services.ErrInsufficientRole),
+		errors.Is(err, services.ErrEmailNotVerified):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrWorkspaceNotFound),
+		errors.Is(err, services.ErrCollectionNotFound),
+		errors.Is(err, services.ErrRequestNotFound),
+		errors.Is(err, services.ErrRevisionNotFound),
+		errors.Is(err, services.ErrEnvironmentNotFound),
+		errors.Is(err, services.ErrCertificateNotFound),
+		errors.Is(err, services.ErrProxyNotFound),
+		errors.Is(err, services.ErrCookieNotFound),
+		errors.Is(err, services.ErrExecutionNotFound),
+		errors.Is(err, httpclient.ErrResponseBodyNotFound),
+		errors.Is(err, services.ErrBlobNotFound),
+		errors.Is(err, services.ErrMemberNotFound),
+		errors.Is(err, services.ErrInvitationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrAlreadyMember),
+		errors.Is(err, services.ErrLastOwner),
+		errors.Is(err, services.ErrPersonalWorkspace),
+		errors.Is(err, services.ErrInvitationNotPending),
+		errors.Is(err, httpclient.ErrExecutionIDInUse):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrBlobTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrInvalidRole),
+		errors.Is(err, services.ErrInvalidAuditFilter),
+		errors.Is(err, services.ErrInvalidVariable),
+		errors.Is(err, services.ErrInvalidEnvironmentFile),
+		errors.Is(err, services.ErrInvalidCertificate),
+		errors.Is(err, services.ErrInvalidProxy),
+		errors.Is(err, services.ErrInvalidCookie),
+		errors.Is(err, httpclient.ErrInvalidExecutionID),
+		errors.Is(err, httpclient.ErrLocalFileUnsupported):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetMyWorkspaces returns all workspaces the user is a member of
 func (h *WorkspaceHandler) GetMyWorkspaces(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -23,11 +77,256 @@ func (h *WorkspaceHandler) GetMyWorkspaces(c *gin.Context) {
 		return
 	}
 
-	workspaces, err := h.workspaceRepo.FindByUserID(userID)
+	workspaces, err := h.workspaceService.GetUserWorkspaces(userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, workspaces)
-}
\ No newline at end of file
+}
+
+// CreateWorkspace creates a new team workspace
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.CreateWorkspaceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(userID, input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// GetWorkspace returns a single workspace
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// UpdateWorkspace renames a workspace
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.UpdateWorkspaceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := h.workspaceService.UpdateWorkspace(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// DeleteWorkspace deletes a workspace
+func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.workspaceService.DeleteWorkspace(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace deleted"})
+}
+
+// ListMembers returns the members of a workspace
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	members, err := h.workspaceService.ListMembers(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// UpdateMember changes a member's role
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.UpdateMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.workspaceService.UpdateMemberRole(userID, c.Param("id"), c.Param("userId"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a member (or lets a member leave)
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(userID, c.Param("id"), c.Param("userId")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// InviteMember invites a user by email
+func (h *WorkspaceHandler) InviteMember(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input services.InviteMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.workspaceService.InviteMember(userID, c.Param("id"), input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations returns pending invitations of a workspace
+func (h *WorkspaceHandler) ListInvitations(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	invitations, err := h.workspaceService.ListWorkspaceInvitations(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation cancels a pending invitation
+func (h *WorkspaceHandler) RevokeInvitation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.workspaceService.RevokeInvitation(userID, c.Param("id"), c.Param("invitationId")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// ListMyInvitations returns pending invitations addressed to the current user
+func (h *WorkspaceHandler) ListMyInvitations(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	invitations, err := h.workspaceService.ListMyInvitations(userID)
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitation joins the invited workspace
+func (h *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	member, err := h.workspaceService.AcceptInvitation(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// DeclineInvitation rejects an invitation
+func (h *WorkspaceHandler) DeclineInvitation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.workspaceService.DeclineInvitation(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/services"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockDB returns a GORM database backed by sqlmock
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+func TestGetWorkspaceHidesSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, mock := newMockDB(t)
+	// Preloads may run in any order; environments are served only if the
+	// route loads them
+	mock.MatchExpectationsInOrder(false)
+
+	workspaceID := uuid.New()
+	secret := "enc:v1:c2VjcmV0LWNpcGhlcnRleHQ="
+	mock.ExpectQuery(`SELECT \* FROM "workspaces"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "userId", "name", "personal"}).
+			AddRow(workspaceID, "user", "Workspace", true))
+	mock.ExpectQuery(`SELECT \* FROM "collections"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name"}))
+	mock.ExpectQuery(`SELECT \* FROM "environments"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name", "variables"}).
+			AddRow(uuid.New(), workspaceID, "Production",
+				`[{"key":"API_KEY","value":"`+secret+`","type":"secret","enabled":true}]`))
+	mock.ExpectQuery(`SELECT \* FROM "workspace_members"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "userId", "role"}).
+			AddRow(uuid.New(), workspaceID, "user", models.RoleViewer))
+
+	handler := NewWorkspaceHandler(services.NewWorkspaceService(
+		repository.NewWorkspaceRepository(db),
+		repository.NewWorkspaceMemberRepository(db),
+		repository.NewWorkspaceInvitationRepository(db),
+		repository.NewUserRepository(db),
+	))
+	router := gin.New()
+	router.GET("/api/workspaces/:id", func(c *gin.Context) {
+		c.Set("user_id", "user")
+	}, handler.GetWorkspace)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID.String(), nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /api/workspaces/:id status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+	if body := recorder.Body.String(); strings.Contains(body, secret) {
+		t.Fatalf("GET /api/workspaces/:id returned a secret value: %s", body)
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/config"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/database"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// rateLimitScript counts a request in the key's window, starting the window
+// on its first request, and returns the count and milliseconds until reset
+var rateLimitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// RateLimitMiddleware limits the requests a user executes per window by plan.
+// It runs after SessionMiddleware. Counts are kept in Redis so every instance
+// shares them; while Redis is unreachable requests are let through.
+func RateLimitMiddleware(cfg *config.Config) gin.HandlerFunc {
+	window := cfg.RateLimit.Window
+
+	return func(c *gin.Context) {
+		userID, exists := GetUserID(c)
+		if !exists || database.Redis == nil {
+			c.Next()
+			return
+		}
+
+		plan, _ := GetUserPlan(c)
+		limit := cfg.RateLimit.Free
+		if plan == string(models.PlanPro) {
+			limit = cfg.RateLimit.Pro
+		} else {
+			plan = string(models.PlanFree)
+		}
+
+		// Keyed by plan as well, so an upgrade starts a fresh window
+		key := fmt.Sprintf("ratelimit:%s:%s", plan, userID)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), time.Second)
+		result, err := rateLimitScript.Run(ctx, database.Redis, []string{key}, window.Milliseconds()).Int64Slice()
+		cancel()
+		if err != nil || len(result) != 2 {
+			log.Printf("Rate limit check failed, allowing request: %v", err)
+			c.Next()
+			return
+		}
+
+		count := int(result[0])
+		untilReset := time.Duration(result[1]) * time.Millisecond
+		resetSeconds := int((untilReset + time.Second - 1) / time.Second)
+		resetAt := time.Now().Add(untilReset).UTC().Truncate(time.Second)
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(max(limit-count, 0)))
+		header.Set("RateLimit-Reset", strconv.Itoa(resetSeconds))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit, int(window.Seconds())))
+
+		if count > limit {
+			header.Set("Retry-After", strconv.Itoa(resetSeconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": fmt.Sprintf("Rate limit exceeded: the %s plan allows %d requests per %s. The limit resets at %s",
+					plan, limit, windowText(window), resetAt.Format(time.RFC3339)),
+				"plan":       plan,
+				"limit":      limit,
+				"window":     int(window.Seconds()),
+				"resetAt":    resetAt,
+				"retryAfter": resetSeconds,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// windowText describes a window such as "hour", "15 minutes" or "day"
+func windowText(window time.Duration) string {
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{24 * time.Hour, "day"},
+		{time.Hour, "hour"},
+		{time.Minute, "minute"},
+		{time.Second, "second"},
+	}
+	for _, unit := range units {
+		if window%unit.size == 0 {
+			if n := int(window / unit.size); n > 1 {
+				return fmt.Sprintf("%d %ss", n, unit.name)
+			}
+			return unit.name
+		}
+	}
+	return window.String()
+}
@@ -119,6 +119,12 @@ func GetUserPlan(c *gin.Context) (string, bool) {
 	if !exists {
 		return "", false
 	}
-	planStr, ok := plan.(string)
-	return planStr, ok
+	// SessionMiddleware stores the user's models.PlanType
+	switch planValue := plan.(type) {
+	case models.PlanType:
+		return string(planValue), true
+	case string:
+		return planValue, true
+	}
+	return "", false
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+type AuditEntity string
+
+const (
+	AuditEntityCollection  AuditEntity = "collection"
+	AuditEntityRequest     AuditEntity = "request"
+	AuditEntityEnvironment AuditEntity = "environment"
+)
+
+// AuditEvent records a change made to a shared workspace resource
+type AuditEvent struct {
+	ID          uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID   `gorm:"type:uuid;not null;index:idx_audit_workspace_created" json:"workspace_id"`
+	ActorID     string      `gorm:"type:varchar(255);not null;index;column:actorId" json:"actorId"`
+	Action      AuditAction `gorm:"type:varchar(20);not null" json:"action"`
+	EntityType  AuditEntity `gorm:"type:varchar(30);not null" json:"entity_type"`
+	EntityID    uuid.UUID   `gorm:"type:uuid;not null;index" json:"entity_id"`
+	EntityName  string      `gorm:"type:varchar(255)" json:"entity_name"`
+	Before      JSONB       `gorm:"type:jsonb;default:'{}'" json:"before"`
+	After       JSONB       `gorm:"type:jsonb;default:'{}'" json:"after"`
+	Changes     JSONB       `gorm:"type:jsonb;default:'{}'" json:"changes"`
+	CreatedAt   time.Time   `gorm:"autoCreateTime;index:idx_audit_workspace_created" json:"created_at"`
+
+	// Read-only actor details filled by listing queries
+	ActorEmail string `gorm:"->;-:migration" json:"actor_email,omitempty"`
+	ActorName  string `gorm:"->;-:migration" json:"actor_name,omitempty"`
+}
+
+func (a *AuditEvent) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.Before == nil {
+		a.Before = make(JSONB)
+	}
+	if a.After == nil {
+		a.After = make(JSONB)
+	}
+	if a.Changes == nil {
+		a.Changes = make(JSONB)
+	}
+	return nil
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Blob is an uploaded file that binary request bodies in a workspace can
+// reference. The content is stored on disk, see BlobService.
+type Blob struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	UserID      string    `gorm:"type:varchar(255);not null;column:userId" json:"userId"` // uploader
+	Name        string    `gorm:"type:varchar(255);not null" json:"name"`
+	ContentType string    `gorm:"type:varchar(255)" json:"contentType"`
+	Size        int64     `gorm:"not null" json:"size"`
+	SHA256      string    `gorm:"type:varchar(64);column:sha256" json:"sha256"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Workspace *Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (b *Blob) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Blob) TableName() string {
+	return "blobs"
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ClientCertificate is an uploaded client certificate and/or CA bundle that
+// is used for requests to hosts matching HostPattern
+type ClientCertificate struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      string     `gorm:"type:varchar(255);not null;index;column:userId" json:"userId"`
+	Name        string     `gorm:"type:varchar(255);not null" json:"name"`
+	HostPattern string     `gorm:"type:varchar(255);not null" json:"hostPattern"`
+	Certificate string     `gorm:"type:text" json:"certificate,omitempty"`
+	PrivateKey  string     `gorm:"type:text" json:"-"` // encrypted with DataKey
+	CA          string     `gorm:"type:text" json:"ca,omitempty"`
+	DataKey     string     `gorm:"type:text" json:"-"`
+	Subject     string     `gorm:"type:varchar(512)" json:"subject,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (c *ClientCertificate) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (ClientCertificate) TableName() string {
+	return "client_certificates"
+}
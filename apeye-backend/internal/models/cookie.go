@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Cookie is a cookie in a workspace's cookie jar
+type Cookie struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_cookie_key" json:"workspace_id"`
+	Domain      string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_cookie_key" json:"domain"`
+	Path        string     `gorm:"type:varchar(1024);not null;uniqueIndex:idx_cookie_key" json:"path"`
+	Name        string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_cookie_key" json:"name"`
+	Value       string     `gorm:"type:text" json:"value"`
+	Expires     *time.Time `json:"expires,omitempty"`
+	Secure      bool       `gorm:"default:false" json:"secure"`
+	HttpOnly    bool       `gorm:"default:false" json:"httpOnly"`
+	HostOnly    bool       `gorm:"default:false" json:"hostOnly"`
+	SameSite    string     `gorm:"type:varchar(10)" json:"sameSite,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace *Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (c *Cookie) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Cookie) TableName() string {
+	return "cookies"
+}
@@ -1,20 +1,87 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+type VariableType string
+
+const (
+	VariableString  VariableType = "string"
+	VariableSecret  VariableType = "secret"
+	VariableNumber  VariableType = "number"
+	VariableBoolean VariableType = "boolean"
+	VariableJSON    VariableType = "json"
+)
+
+// IsValid reports whether t is a known variable type
+func (t VariableType) IsValid() bool {
+	switch t {
+	case VariableString, VariableSecret, VariableNumber, VariableBoolean, VariableJSON:
+		return true
+	}
+	return false
+}
+
+// Variable is a single environment variable. Values are always stored as
+// strings; the type only drives validation and masking.
+type Variable struct {
+	Key         string       `json:"key"`
+	Value       string       `json:"value"`
+	Type        VariableType `json:"type"`
+	Description string       `json:"description"`
+	Enabled     bool         `json:"enabled"`
+}
+
+// VariableList type for PostgreSQL JSONB arrays of variables, kept in order
+type VariableList []Variable
+
+func (l VariableList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *VariableList) Scan(value interface{}) error {
+	if value == nil {
+		*l = VariableList{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Find returns the variable with the given key
+func (l VariableList) Find(key string) (*Variable, bool) {
+	for i := range l {
+		if l[i].Key == key {
+			return &l[i], true
+		}
+	}
+	return nil, false
+}
+
 type Environment struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	WorkspaceID uuid.UUID `gorm:"type:uuid;not null;index" json:"workspace_id"`
-	Name        string    `gorm:"type:varchar(255);not null" json:"name" binding:"required"`
-	Variables   JSONB     `gorm:"type:jsonb;default:'{}'" json:"variables"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
-	
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID    `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Name        string       `gorm:"type:varchar(255);not null" json:"name" binding:"required"`
+	Variables   VariableList `gorm:"type:jsonb;default:'[]'" json:"variables"`
+	DataKey     string       `gorm:"type:text" json:"-"` // per-environment key for secrets, wrapped with the master key
+	CreatedAt   time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
+
 	// Relationships
 	Workspace Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
 }
@@ -24,11 +91,11 @@ func (e *Environment) BeforeCreate(tx *gorm.DB) error {
 		e.ID = uuid.New()
 	}
 	if e.Variables == nil {
-		e.Variables = make(JSONB)
+		e.Variables = VariableList{}
 	}
 	return nil
 }
 
 func (Environment) TableName() string {
 	return "environments"
-}
\ No newline at end of file
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StringList type for PostgreSQL JSONB arrays of strings
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = StringList{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// ProxySetting is the proxy used for a workspace's requests unless a
+// request sets its own
+type ProxySetting struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"workspace_id"`
+	URL         string     `gorm:"type:varchar(2048);not null" json:"url"`
+	Username    string     `gorm:"type:varchar(255)" json:"username,omitempty"`
+	Password    string     `gorm:"type:text" json:"password,omitempty"` // encrypted with DataKey, masked in responses
+	Bypass      StringList `gorm:"type:jsonb;default:'[]'" json:"bypass"`
+	DataKey     string     `gorm:"type:text" json:"-"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace *Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (p *ProxySetting) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	if p.Bypass == nil {
+		p.Bypass = StringList{}
+	}
+	return nil
+}
+
+func (ProxySetting) TableName() string {
+	return "proxy_settings"
+}
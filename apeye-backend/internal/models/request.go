@@ -20,6 +20,7 @@ const (
 	MethodPATCH   HTTPMethod = "PATCH"
 	MethodHEAD    HTTPMethod = "HEAD"
 	MethodOPTIONS HTTPMethod = "OPTIONS"
+	MethodGRPC    HTTPMethod = "GRPC" // history entries of gRPC calls
 )
 
 type AuthType string
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RevisionEntity string
+
+const (
+	RevisionCollection RevisionEntity = "collection"
+	RevisionRequest    RevisionEntity = "request"
+)
+
+// Revision is an immutable snapshot of a collection or saved request
+type Revision struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	EntityType  RevisionEntity `gorm:"type:varchar(30);not null;uniqueIndex:idx_revision_version" json:"entity_type"`
+	EntityID    uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_revision_version" json:"entity_id"`
+	Version     int            `gorm:"type:int;not null;uniqueIndex:idx_revision_version" json:"version"`
+	Snapshot    JSONB          `gorm:"type:jsonb;default:'{}'" json:"snapshot"`
+	AuthorID    string         `gorm:"type:varchar(255);column:authorId" json:"authorId"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (r *Revision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.Snapshot == nil {
+		r.Snapshot = make(JSONB)
+	}
+	return nil
+}
+
+func (Revision) TableName() string {
+	return "revisions"
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VariableSetScope string
+
+const (
+	VariableSetGlobal     VariableSetScope = "global"     // per user
+	VariableSetWorkspace  VariableSetScope = "workspace"  // shared by a workspace
+	VariableSetCollection VariableSetScope = "collection" // shared by a collection
+)
+
+// VariableSet holds the variables of a scope other than an environment.
+// Exactly one of UserID, WorkspaceID and CollectionID is set, matching Scope.
+type VariableSet struct {
+	ID           uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Scope        VariableSetScope `gorm:"type:varchar(20);not null" json:"scope"`
+	UserID       *string          `gorm:"type:varchar(255);uniqueIndex;column:userId" json:"userId,omitempty"`
+	WorkspaceID  *uuid.UUID       `gorm:"type:uuid;uniqueIndex" json:"workspace_id,omitempty"`
+	CollectionID *uuid.UUID       `gorm:"type:uuid;uniqueIndex" json:"collection_id,omitempty"`
+	Variables    VariableList     `gorm:"type:jsonb;default:'[]'" json:"variables"`
+	DataKey      string           `gorm:"type:text" json:"-"` // key for secrets, wrapped with the master key
+	CreatedAt    time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace  *Workspace  `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"-"`
+	Collection *Collection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (v *VariableSet) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	if v.Variables == nil {
+		v.Variables = VariableList{}
+	}
+	return nil
+}
+
+func (VariableSet) TableName() string {
+	return "variable_sets"
+}
@@ -11,13 +11,18 @@ type Workspace struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID    string    `gorm:"type:varchar(255);not null;index;column:userId" json:"userId"`
 	Name      string    `gorm:"type:varchar(255);not null" json:"name" binding:"required"`
+	Personal  bool      `gorm:"not null;default:false" json:"personal"` // the user's default workspace, which "default" refers to
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
+	// Role of the requesting user, filled by membership queries
+	Role WorkspaceRole `gorm:"->;-:migration" json:"role,omitempty"`
+
 	// Relationships - User is managed by Better-Auth, use "-" to skip auto-migration
-	User         User          `gorm:"foreignKey:UserID;references:ID;-" json:"user,omitempty"`
-	Collections  []Collection  `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"collections,omitempty"`
-	Environments []Environment `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"environments,omitempty"`
+	User         User              `gorm:"foreignKey:UserID;references:ID;-" json:"user,omitempty"`
+	Collections  []Collection      `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"collections,omitempty"`
+	Environments []Environment     `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"environments,omitempty"`
+	Members      []WorkspaceMember `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"members,omitempty"`
 }
 
 func (w *Workspace) BeforeCreate(tx *gorm.DB) error {
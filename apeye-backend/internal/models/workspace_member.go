@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkspaceRole string
+
+const (
+	RoleOwner  WorkspaceRole = "owner"
+	RoleEditor WorkspaceRole = "editor"
+	RoleViewer WorkspaceRole = "viewer"
+)
+
+// rank orders roles so that a higher role includes every permission of a lower one
+func (r WorkspaceRole) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsValid reports whether r is one of the known roles
+func (r WorkspaceRole) IsValid() bool {
+	return r.rank() > 0
+}
+
+// Allows reports whether r grants at least the permissions of required
+func (r WorkspaceRole) Allows(required WorkspaceRole) bool {
+	return r.rank() >= required.rank() && r.rank() > 0
+}
+
+// WorkspaceMember links a user to a workspace with a role
+type WorkspaceMember struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_workspace_member" json:"workspace_id"`
+	UserID      string        `gorm:"type:varchar(255);not null;uniqueIndex:idx_workspace_member;index;column:userId" json:"userId"`
+	Role        WorkspaceRole `gorm:"type:varchar(20);not null" json:"role"`
+	CreatedAt   time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Read-only user details filled by member listing queries
+	Email string `gorm:"->;-:migration" json:"email,omitempty"`
+	Name  string `gorm:"->;-:migration" json:"name,omitempty"`
+
+	// Relationships
+	Workspace Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (m *WorkspaceMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+func (WorkspaceMember) TableName() string {
+	return "workspace_members"
+}
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+	InvitationRevoked  InvitationStatus = "revoked"
+)
+
+// WorkspaceInvitation invites an email address to join a workspace
+type WorkspaceInvitation struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID uuid.UUID        `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Email       string           `gorm:"type:varchar(255);not null;index" json:"email"`
+	Role        WorkspaceRole    `gorm:"type:varchar(20);not null" json:"role"`
+	Status      InvitationStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	InvitedBy   string           `gorm:"type:varchar(255);not null;column:invitedBy" json:"invitedBy"`
+	ExpiresAt   time.Time        `gorm:"type:timestamp;not null" json:"expires_at"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
+}
+
+func (i *WorkspaceInvitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	if i.Status == "" {
+		i.Status = InvitationPending
+	}
+	return nil
+}
+
+func (WorkspaceInvitation) TableName() string {
+	return "workspace_invitations"
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// AuditFilter narrows down audit event queries
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   *uuid.UUID
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
+
+// Create records an audit event
+func (r *AuditRepository) Create(event *models.AuditEvent) error {
+	return r.db.Create(event).Error
+}
+
+// FindByWorkspaceID returns a page of audit events for a workspace, newest first,
+// together with the total number of events matching the filter
+func (r *AuditRepository) FindByWorkspaceID(workspaceID uuid.UUID, filter AuditFilter) ([]models.AuditEvent, int64, error) {
+	matches := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("audit_events.workspace_id = ?", workspaceID)
+		if filter.ActorID != "" {
+			db = db.Where(`audit_events."actorId" = ?`, filter.ActorID)
+		}
+		if filter.Action != "" {
+			db = db.Where("audit_events.action = ?", filter.Action)
+		}
+		if filter.EntityType != "" {
+			db = db.Where("audit_events.entity_type = ?", filter.EntityType)
+		}
+		if filter.EntityID != nil {
+			db = db.Where("audit_events.entity_id = ?", *filter.EntityID)
+		}
+		if filter.Since != nil {
+			db = db.Where("audit_events.created_at >= ?", *filter.Since)
+		}
+		if filter.Until != nil {
+			db = db.Where("audit_events.created_at < ?", *filter.Until)
+		}
+		return db
+	}
+
+	var total int64
+	if err := r.db.Model(&models.AuditEvent{}).Scopes(matches).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.AuditEvent
+	err := r.db.Scopes(matches).
+		Select(`audit_events.*, "user".email AS actor_email, "user".name AS actor_name`).
+		Joins(`LEFT JOIN "user" ON "user".id = audit_events."actorId"`).
+		Order("audit_events.created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BlobRepository struct {
+	db *gorm.DB
+}
+
+func NewBlobRepository(db *gorm.DB) *BlobRepository {
+	return &BlobRepository{db: db}
+}
+
+// Create creates a new blob
+func (r *BlobRepository) Create(blob *models.Blob) error {
+	return r.db.Create(blob).Error
+}
+
+// FindByID finds a blob by ID
+func (r *BlobRepository) FindByID(id uuid.UUID) (*models.Blob, error) {
+	var blob models.Blob
+	err := r.db.Where("id = ?", id).First(&blob).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &blob, nil
+}
+
+// FindByWorkspaceID finds the blobs of a workspace, newest first
+func (r *BlobRepository) FindByWorkspaceID(workspaceID uuid.UUID) ([]models.Blob, error) {
+	var blobs []models.Blob
+	err := r.db.Where("workspace_id = ?", workspaceID).Order("created_at DESC").Find(&blobs).Error
+	return blobs, err
+}
+
+// Delete deletes a blob
+func (r *BlobRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Blob{}, "id = ?", id).Error
+}
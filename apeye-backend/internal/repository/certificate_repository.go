@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CertificateRepository struct {
+	db *gorm.DB
+}
+
+func NewCertificateRepository(db *gorm.DB) *CertificateRepository {
+	return &CertificateRepository{db: db}
+}
+
+// Create creates a new client certificate
+func (r *CertificateRepository) Create(cert *models.ClientCertificate) error {
+	return r.db.Create(cert).Error
+}
+
+// FindByID finds a client certificate by ID
+func (r *CertificateRepository) FindByID(id uuid.UUID) (*models.ClientCertificate, error) {
+	var cert models.ClientCertificate
+	err := r.db.Where("id = ?", id).First(&cert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// FindByUserID finds all client certificates of a user, oldest first
+func (r *CertificateRepository) FindByUserID(userID string) ([]models.ClientCertificate, error) {
+	var certs []models.ClientCertificate
+	err := r.db.Where(`"userId" = ?`, userID).Order("created_at ASC").Find(&certs).Error
+	return certs, err
+}
+
+// Delete deletes a client certificate
+func (r *CertificateRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.ClientCertificate{}, "id = ?", id).Error
+}
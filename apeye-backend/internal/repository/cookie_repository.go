@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CookieRepository struct {
+	db *gorm.DB
+}
+
+func NewCookieRepository(db *gorm.DB) *CookieRepository {
+	return &CookieRepository{db: db}
+}
+
+// Upsert creates a cookie or replaces the one with the same domain, path and name
+func (r *CookieRepository) Upsert(cookie *models.Cookie) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "domain"}, {Name: "path"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires", "secure", "http_only", "host_only", "same_site", "updated_at"}),
+	}).Create(cookie).Error
+}
+
+// Update saves changes to a cookie
+func (r *CookieRepository) Update(cookie *models.Cookie) error {
+	return r.db.Save(cookie).Error
+}
+
+// FindByID finds a cookie by ID
+func (r *CookieRepository) FindByID(id uuid.UUID) (*models.Cookie, error) {
+	var cookie models.Cookie
+	err := r.db.Where("id = ?", id).First(&cookie).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cookie, nil
+}
+
+// FindByWorkspaceID finds the cookies of a workspace, optionally for one domain
+func (r *CookieRepository) FindByWorkspaceID(workspaceID uuid.UUID, domain string) ([]models.Cookie, error) {
+	var cookies []models.Cookie
+	query := r.db.Where("workspace_id = ?", workspaceID)
+	if domain != "" {
+		query = query.Where("domain = ?", domain)
+	}
+	err := query.Order("domain ASC, path ASC, name ASC").Find(&cookies).Error
+	return cookies, err
+}
+
+// Delete deletes a cookie
+func (r *CookieRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Cookie{}, "id = ?", id).Error
+}
+
+// DeleteByKey deletes the cookie with the given domain, path and name
+func (r *CookieRepository) DeleteByKey(workspaceID uuid.UUID, domain, path, name string) error {
+	return r.db.Where("workspace_id = ? AND domain = ? AND path = ? AND name = ?", workspaceID, domain, path, name).
+		Delete(&models.Cookie{}).Error
+}
+
+// DeleteByWorkspaceID clears a workspace's cookies, optionally only for one domain
+func (r *CookieRepository) DeleteByWorkspaceID(workspaceID uuid.UUID, domain string) error {
+	query := r.db.Where("workspace_id = ?", workspaceID)
+	if domain != "" {
+		query = query.Where("domain = ?", domain)
+	}
+	return query.Delete(&models.Cookie{}).Error
+}
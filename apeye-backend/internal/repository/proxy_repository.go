@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProxyRepository struct {
+	db *gorm.DB
+}
+
+func NewProxyRepository(db *gorm.DB) *ProxyRepository {
+	return &ProxyRepository{db: db}
+}
+
+// Save creates or updates a proxy setting
+func (r *ProxyRepository) Save(setting *models.ProxySetting) error {
+	return r.db.Save(setting).Error
+}
+
+// FindByWorkspaceID finds the proxy setting of a workspace
+func (r *ProxyRepository) FindByWorkspaceID(workspaceID uuid.UUID) (*models.ProxySetting, error) {
+	var setting models.ProxySetting
+	err := r.db.Where("workspace_id = ?", workspaceID).First(&setting).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// DeleteByWorkspaceID removes the proxy setting of a workspace
+func (r *ProxyRepository) DeleteByWorkspaceID(workspaceID uuid.UUID) error {
+	return r.db.Where("workspace_id = ?", workspaceID).Delete(&models.ProxySetting{}).Error
+}
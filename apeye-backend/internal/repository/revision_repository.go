@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) *RevisionRepository {
+	return &RevisionRepository{db: db}
+}
+
+// Create stores a revision as the next version of its entity. When the entity
+// has no revisions yet and baseline is not nil, baseline is stored first as
+// version 1. Saves of the same entity are serialized with a transaction-scoped
+// advisory lock on it, which works for every entity type, so concurrent saves
+// don't both take the same version or both store a baseline.
+func (r *RevisionRepository) Create(revision *models.Revision, baseline models.JSONB) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		lockKey := string(revision.EntityType) + ":" + revision.EntityID.String()
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", lockKey).Error; err != nil {
+			return err
+		}
+
+		var latest int
+		err := tx.Model(&models.Revision{}).
+			Where("entity_type = ? AND entity_id = ?", revision.EntityType, revision.EntityID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+
+		if latest == 0 && baseline != nil {
+			latest++
+			err := tx.Create(&models.Revision{
+				WorkspaceID: revision.WorkspaceID,
+				EntityType:  revision.EntityType,
+				EntityID:    revision.EntityID,
+				Version:     latest,
+				Snapshot:    baseline,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		revision.Version = latest + 1
+		return tx.Create(revision).Error
+	})
+}
+
+// FindByEntity lists all revisions of an entity, newest first
+func (r *RevisionRepository) FindByEntity(entityType models.RevisionEntity, entityID uuid.UUID) ([]models.Revision, error) {
+	var revisions []models.Revision
+	err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("version DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// FindByVersion finds a single revision of an entity
+func (r *RevisionRepository) FindByVersion(entityType models.RevisionEntity, entityID uuid.UUID, version int) (*models.Revision, error) {
+	var revision models.Revision
+	err := r.db.Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).
+		First(&revision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
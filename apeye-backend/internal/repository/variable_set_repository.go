@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VariableSetRepository struct {
+	db *gorm.DB
+}
+
+func NewVariableSetRepository(db *gorm.DB) *VariableSetRepository {
+	return &VariableSetRepository{db: db}
+}
+
+// Save creates or updates a variable set
+func (r *VariableSetRepository) Save(set *models.VariableSet) error {
+	return r.db.Save(set).Error
+}
+
+// FindByUserID finds the global variables of a user
+func (r *VariableSetRepository) FindByUserID(userID string) (*models.VariableSet, error) {
+	return r.findOne(`"userId" = ?`, userID)
+}
+
+// FindByWorkspaceID finds the global variables of a workspace
+func (r *VariableSetRepository) FindByWorkspaceID(workspaceID uuid.UUID) (*models.VariableSet, error) {
+	return r.findOne("workspace_id = ?", workspaceID)
+}
+
+// FindByCollectionID finds the variables of a collection
+func (r *VariableSetRepository) FindByCollectionID(collectionID uuid.UUID) (*models.VariableSet, error) {
+	return r.findOne("collection_id = ?", collectionID)
+}
+
+func (r *VariableSetRepository) findOne(query string, args ...interface{}) (*models.VariableSet, error) {
+	var set models.VariableSet
+	err := r.db.Where(query, args...).First(&set).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &set, nil
+}
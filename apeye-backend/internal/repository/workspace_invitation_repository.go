@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkspaceInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceInvitationRepository(db *gorm.DB) *WorkspaceInvitationRepository {
+	return &WorkspaceInvitationRepository{db: db}
+}
+
+// Create creates a new invitation
+func (r *WorkspaceInvitationRepository) Create(invitation *models.WorkspaceInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// FindByID finds an invitation by ID
+func (r *WorkspaceInvitationRepository) FindByID(id uuid.UUID) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	err := r.db.First(&invitation, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindByWorkspaceID lists pending invitations for a workspace
+func (r *WorkspaceInvitationRepository) FindByWorkspaceID(workspaceID uuid.UUID) ([]models.WorkspaceInvitation, error) {
+	var invitations []models.WorkspaceInvitation
+	err := r.db.Where("workspace_id = ? AND status = ?", workspaceID, models.InvitationPending).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// FindPendingByEmail lists unexpired pending invitations addressed to an email
+func (r *WorkspaceInvitationRepository) FindPendingByEmail(email string) ([]models.WorkspaceInvitation, error) {
+	var invitations []models.WorkspaceInvitation
+	err := r.db.Where("LOWER(email) = LOWER(?) AND status = ? AND expires_at > ?", email, models.InvitationPending, time.Now().UTC()).
+		Preload("Workspace").
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// FindPendingByWorkspaceAndEmail finds an open invitation for an email in a workspace
+func (r *WorkspaceInvitationRepository) FindPendingByWorkspaceAndEmail(workspaceID uuid.UUID, email string) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	err := r.db.Where("workspace_id = ? AND LOWER(email) = LOWER(?) AND status = ?", workspaceID, email, models.InvitationPending).
+		First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// Update updates an invitation
+func (r *WorkspaceInvitationRepository) Update(invitation *models.WorkspaceInvitation) error {
+	return r.db.Save(invitation).Error
+}
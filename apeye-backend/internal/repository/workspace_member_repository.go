@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkspaceMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceMemberRepository(db *gorm.DB) *WorkspaceMemberRepository {
+	return &WorkspaceMemberRepository{db: db}
+}
+
+// Create adds a member to a workspace
+func (r *WorkspaceMemberRepository) Create(member *models.WorkspaceMember) error {
+	return r.db.Create(member).Error
+}
+
+// FindByWorkspaceAndUser finds a user's membership in a workspace
+func (r *WorkspaceMemberRepository) FindByWorkspaceAndUser(workspaceID uuid.UUID, userID string) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := r.db.Where(`workspace_id = ? AND "userId" = ?`, workspaceID, userID).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindByWorkspaceID lists all members of a workspace along with their user details
+func (r *WorkspaceMemberRepository) FindByWorkspaceID(workspaceID uuid.UUID) ([]models.WorkspaceMember, error) {
+	var members []models.WorkspaceMember
+	err := r.db.Table("workspace_members").
+		Select(`workspace_members.*, "user".email AS email, "user".name AS name`).
+		Joins(`LEFT JOIN "user" ON "user".id = workspace_members."userId"`).
+		Where("workspace_members.workspace_id = ?", workspaceID).
+		Order("workspace_members.created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// CountByRole counts members of a workspace holding the given role
+func (r *WorkspaceMemberRepository) CountByRole(workspaceID uuid.UUID, role models.WorkspaceRole) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, role).
+		Count(&count).Error
+	return count, err
+}
+
+// Update updates a membership
+func (r *WorkspaceMemberRepository) Update(member *models.WorkspaceMember) error {
+	return r.db.Save(member).Error
+}
+
+// Delete removes a user from a workspace
+func (r *WorkspaceMemberRepository) Delete(workspaceID uuid.UUID, userID string) error {
+	return r.db.Where(`workspace_id = ? AND "userId" = ?`, workspaceID, userID).
+		Delete(&models.WorkspaceMember{}).Error
+}
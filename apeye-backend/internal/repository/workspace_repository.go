@@ -16,15 +16,24 @@ func NewWorkspaceRepository(db *gorm.DB) *WorkspaceRepository {
 	return &WorkspaceRepository{db: db}
 }
 
-// Create creates a new workspace
+// Create creates a new workspace and makes its creator the owner
 func (r *WorkspaceRepository) Create(workspace *models.Workspace) error {
-	return r.db.Create(workspace).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      workspace.UserID,
+			Role:        models.RoleOwner,
+		}).Error
+	})
 }
 
 // FindByID finds a workspace by ID
 func (r *WorkspaceRepository) FindByID(id uuid.UUID) (*models.Workspace, error) {
 	var workspace models.Workspace
-	err := r.db.Preload("Collections").Preload("Environments").
+	err := r.db.Preload("Collections").
 		First(&workspace, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -35,32 +44,38 @@ func (r *WorkspaceRepository) FindByID(id uuid.UUID) (*models.Workspace, error)
 	return &workspace, nil
 }
 
-// FindByUserID finds all workspaces for a user
+// FindByUserID finds all workspaces the user is a member of
 func (r *WorkspaceRepository) FindByUserID(userID string) ([]models.Workspace, error) {
 	var workspaces []models.Workspace
 	//case-sensitive match (annoying bug)
-	err := r.db.Where(`"userId" = ?`, userID).
+	err := r.db.Select("workspaces.*, workspace_members.role AS role").
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where(`workspace_members."userId" = ?`, userID).
 		Preload("Collections").
-		Order("created_at DESC").
+		Order("workspaces.created_at DESC").
 		Find(&workspaces).Error
 	return workspaces, err
 }
 
 // FindDefaultByUserID finds or creates default workspace for user
+// (the personal workspace the user created)
 func (r *WorkspaceRepository) FindDefaultByUserID(userID string) (*models.Workspace, error) {
 	var workspace models.Workspace
-	//case-sensitive match (annoying bug)
-	err := r.db.Where(`"userId" = ?`, userID).
-		Order("created_at ASC").
-		First(&workspace).Error
+	err := r.db.Where(`"userId" = ? AND personal`, userID).First(&workspace).Error
 	
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		// Create default workspace
 		workspace = models.Workspace{
-			UserID: userID,
-			Name:   "My Workspace",
+			UserID:   userID,
+			Name:     "My Workspace",
+			Personal: true,
 		}
 		if err := r.Create(&workspace); err != nil {
+			// A concurrent call may have created it first
+			var existing models.Workspace
+			if r.db.Where(`"userId" = ? AND personal`, userID).First(&existing).Error == nil {
+				return &existing, nil
+			}
 			return nil, err
 		}
 		return &workspace, nil
@@ -14,6 +14,13 @@ func SetupRoutes(
 	collectionHandler *handlers.CollectionHandler,
 	historyHandler *handlers.HistoryHandler,
 	environmentHandler *handlers.EnvironmentHandler,
+	workspaceHandler *handlers.WorkspaceHandler,
+	auditHandler *handlers.AuditHandler,
+	variableHandler *handlers.VariableHandler,
+	certificateHandler *handlers.CertificateHandler,
+	proxyHandler *handlers.ProxyHandler,
+	cookieHandler *handlers.CookieHandler,
+	blobHandler *handlers.BlobHandler,
 ) {
 	// API group
 	api := router.Group("/api")
@@ -26,10 +33,16 @@ func SetupRoutes(
 			})
 		})
 
+		// WebSocket relay, authorized by the session created below
+		api.GET("/websocket/sessions/:id", requestHandler.ConnectWebSocket)
+
 		// Protected routes - require Better-Auth session
 		protected := api.Group("")
 		protected.Use(middleware.SessionMiddleware(cfg))
 		{
+			// Routes that send requests out count against the plan's rate limit
+			rateLimited := middleware.RateLimitMiddleware(cfg)
+
 			// User info
 			protected.GET("/user/me", func(c *gin.Context) {
 				userID, _ := c.Get("user_id")
@@ -43,8 +56,69 @@ func SetupRoutes(
 				})
 			})
 
+			// Workspaces
+			protected.GET("/workspaces", workspaceHandler.GetMyWorkspaces)
+			protected.POST("/workspaces", workspaceHandler.CreateWorkspace)
+			protected.GET("/workspaces/:id", workspaceHandler.GetWorkspace)
+			protected.PUT("/workspaces/:id", workspaceHandler.UpdateWorkspace)
+			protected.DELETE("/workspaces/:id", workspaceHandler.DeleteWorkspace)
+
+			// Workspace members and invitations
+			protected.GET("/workspaces/:id/members", workspaceHandler.ListMembers)
+			protected.PUT("/workspaces/:id/members/:userId", workspaceHandler.UpdateMember)
+			protected.DELETE("/workspaces/:id/members/:userId", workspaceHandler.RemoveMember)
+			protected.GET("/workspaces/:id/invitations", workspaceHandler.ListInvitations)
+			protected.POST("/workspaces/:id/invitations", workspaceHandler.InviteMember)
+			protected.DELETE("/workspaces/:id/invitations/:invitationId", workspaceHandler.RevokeInvitation)
+			protected.GET("/invitations", workspaceHandler.ListMyInvitations)
+			protected.POST("/invitations/:id/accept", workspaceHandler.AcceptInvitation)
+			protected.POST("/invitations/:id/decline", workspaceHandler.DeclineInvitation)
+
+			// Workspace proxy
+			protected.GET("/workspaces/:id/proxy", proxyHandler.GetWorkspaceProxy)
+			protected.PUT("/workspaces/:id/proxy", proxyHandler.UpdateWorkspaceProxy)
+			protected.DELETE("/workspaces/:id/proxy", proxyHandler.DeleteWorkspaceProxy)
+
+			// Workspace cookie jar
+			protected.GET("/workspaces/:id/cookies", cookieHandler.ListCookies)
+			protected.POST("/workspaces/:id/cookies", cookieHandler.SetCookie)
+			protected.DELETE("/workspaces/:id/cookies", cookieHandler.ClearCookies)
+			protected.PUT("/workspaces/:id/cookies/:cookieId", cookieHandler.UpdateCookie)
+			protected.DELETE("/workspaces/:id/cookies/:cookieId", cookieHandler.DeleteCookie)
+
+			// Files for binary request bodies
+			protected.GET("/workspaces/:id/blobs", blobHandler.ListBlobs)
+			protected.POST("/workspaces/:id/blobs", blobHandler.UploadBlob)
+			protected.DELETE("/workspaces/:id/blobs/:blobId", blobHandler.DeleteBlob)
+
+			// Audit log
+			protected.GET("/workspaces/:id/audit", auditHandler.ListAuditEvents)
+
+			// Variables (environments are managed below)
+			protected.GET("/variables/global", variableHandler.GetGlobalVariables)
+			protected.PUT("/variables/global", variableHandler.UpdateGlobalVariables)
+			protected.POST("/variables/preview", variableHandler.PreviewVariables)
+			protected.GET("/workspaces/:id/variables", variableHandler.GetWorkspaceVariables)
+			protected.PUT("/workspaces/:id/variables", variableHandler.UpdateWorkspaceVariables)
+			protected.GET("/collections/:id/variables", variableHandler.GetCollectionVariables)
+			protected.PUT("/collections/:id/variables", variableHandler.UpdateCollectionVariables)
+
+			// Client certificates for mTLS
+			protected.GET("/certificates", certificateHandler.ListCertificates)
+			protected.POST("/certificates", certificateHandler.CreateCertificate)
+			protected.DELETE("/certificates/:id", certificateHandler.DeleteCertificate)
+
 			// Execute API request
-			protected.POST("/requests/execute", requestHandler.ExecuteRequest)
+			protected.POST("/requests/execute", rateLimited, requestHandler.ExecuteRequest)
+			protected.POST("/requests/execute/stream", rateLimited, requestHandler.ExecuteStream)
+			protected.GET("/requests/executions", requestHandler.ListExecutions)
+			protected.POST("/requests/executions/:id/cancel", requestHandler.CancelExecution)
+			protected.GET("/requests/bodies/:id", requestHandler.DownloadResponseBody)
+			protected.POST("/websocket/sessions", rateLimited, requestHandler.CreateWebSocketSession)
+			protected.POST("/graphql/introspect", rateLimited, requestHandler.IntrospectGraphQL)
+			protected.POST("/grpc/services", rateLimited, requestHandler.ListGRPCServices)
+			protected.POST("/grpc/execute", rateLimited, requestHandler.ExecuteGRPC)
+			protected.POST("/oauth2/authorize", requestHandler.StartOAuth2Authorization)
 
 			// Collections
 			protected.GET("/collections", collectionHandler.ListCollections)
@@ -52,10 +126,19 @@ func SetupRoutes(
 			protected.GET("/collections/:id", collectionHandler.GetCollection)
 			protected.PUT("/collections/:id", collectionHandler.UpdateCollection)
 			protected.DELETE("/collections/:id", collectionHandler.DeleteCollection)
+			protected.GET("/collections/:id/revisions", collectionHandler.ListCollectionRevisions)
+			protected.GET("/collections/:id/revisions/diff", collectionHandler.DiffCollectionRevisions)
+			protected.GET("/collections/:id/revisions/:version", collectionHandler.GetCollectionRevision)
+			protected.POST("/collections/:id/revisions/:version/restore", collectionHandler.RestoreCollectionRevision)
 
 			// Saved Requests
 			protected.POST("/requests", collectionHandler.SaveRequest)
+			protected.PUT("/requests/:id", collectionHandler.UpdateRequest)
 			protected.DELETE("/requests/:id", collectionHandler.DeleteRequest)
+			protected.GET("/requests/:id/revisions", collectionHandler.ListRequestRevisions)
+			protected.GET("/requests/:id/revisions/diff", collectionHandler.DiffRequestRevisions)
+			protected.GET("/requests/:id/revisions/:version", collectionHandler.GetRequestRevision)
+			protected.POST("/requests/:id/revisions/:version/restore", collectionHandler.RestoreRequestRevision)
 
 			// History
 			protected.GET("/history", historyHandler.ListHistory)
@@ -69,6 +152,10 @@ func SetupRoutes(
 			protected.GET("/environments/:id", environmentHandler.GetEnvironment)
 			protected.PUT("/environments/:id", environmentHandler.UpdateEnvironment)
 			protected.DELETE("/environments/:id", environmentHandler.DeleteEnvironment)
+			protected.POST("/environments/:id/clone", environmentHandler.CloneEnvironment)
+			protected.GET("/environments/:id/diff", environmentHandler.DiffEnvironments)
+			protected.POST("/environments/:id/import", environmentHandler.ImportEnvironment)
+			protected.GET("/environments/:id/export", environmentHandler.ExportEnvironment)
 		}
 	}
 }
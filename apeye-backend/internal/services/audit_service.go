@@ -0,0 +1,241 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+type AuditService struct {
+	auditRepo        *repository.AuditRepository
+	workspaceService *WorkspaceService
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository, workspaceService *WorkspaceService) *AuditService {
+	return &AuditService{
+		auditRepo:        auditRepo,
+		workspaceService: workspaceService,
+	}
+}
+
+// ListAuditEventsInput holds the query filters for the audit log
+type ListAuditEventsInput struct {
+	ActorID    string `form:"actor_id"`
+	Action     string `form:"action"`
+	EntityType string `form:"entity_type"`
+	EntityID   string `form:"entity_id"`
+	Since      string `form:"since"`
+	Until      string `form:"until"`
+	Limit      int    `form:"limit"`
+	Offset     int    `form:"offset"`
+}
+
+// AuditEventPage is a single page of audit events
+type AuditEventPage struct {
+	Events []models.AuditEvent `json:"events"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+// Record stores an audit event. Snapshots are plain maps of the entity's fields;
+// before is nil for creations and after is nil for deletions.
+// Failures are logged rather than returned so auditing never blocks the change itself.
+func (s *AuditService) Record(
+	workspaceID uuid.UUID,
+	actorID string,
+	action models.AuditAction,
+	entityType models.AuditEntity,
+	entityID uuid.UUID,
+	entityName string,
+	before, after models.JSONB,
+) {
+	event := &models.AuditEvent{
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		Action:      action,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		EntityName:  entityName,
+		Before:      before,
+		After:       after,
+		Changes:     diffSnapshots(before, after),
+	}
+
+	if err := s.auditRepo.Create(event); err != nil {
+		log.Printf("Failed to record audit event %s %s %s: %v", action, entityType, entityID, err)
+	}
+}
+
+// ListWorkspaceEvents returns the audit log of a workspace the user can view
+func (s *AuditService) ListWorkspaceEvents(userID string, workspaceID string, input ListAuditEventsInput) (*AuditEventPage, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.AuditFilter{
+		ActorID:    input.ActorID,
+		Action:     input.Action,
+		EntityType: input.EntityType,
+		Limit:      input.Limit,
+		Offset:     input.Offset,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	if input.EntityID != "" {
+		id, err := uuid.Parse(input.EntityID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: entity_id must be a UUID", ErrInvalidAuditFilter)
+		}
+		filter.EntityID = &id
+	}
+	if input.Since != "" {
+		since, err := time.Parse(time.RFC3339, input.Since)
+		if err != nil {
+			return nil, fmt.Errorf("%w: since must be an RFC3339 timestamp", ErrInvalidAuditFilter)
+		}
+		filter.Since = &since
+	}
+	if input.Until != "" {
+		until, err := time.Parse(time.RFC3339, input.Until)
+		if err != nil {
+			return nil, fmt.Errorf("%w: until must be an RFC3339 timestamp", ErrInvalidAuditFilter)
+		}
+		filter.Until = &until
+	}
+
+	events, total, err := s.auditRepo.FindByWorkspaceID(workspace.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditEventPage{
+		Events: events,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// collectionSnapshot captures the audited fields of a collection
+func collectionSnapshot(collection *models.Collection) models.JSONB {
+	return models.JSONB{
+		"name":        collection.Name,
+		"description": collection.Description,
+	}
+}
+
+// requestSnapshot captures the audited fields of a saved request
+func requestSnapshot(request *models.Request) models.JSONB {
+	return toJSONB(map[string]interface{}{
+		"collection_id": request.CollectionID,
+		"name":          request.Name,
+		"method":        request.Method,
+		"url":           request.URL,
+		"headers":       request.Headers,
+		"params":        request.Params,
+		"auth":          request.Auth,
+		"body":          request.Body,
+	})
+}
+
+// environmentSnapshot captures the audited fields of an environment. Variables
+// are keyed by name so changes are reported per variable; secret values are
+// only present as ciphertext.
+func environmentSnapshot(environment *models.Environment) models.JSONB {
+	variables := make(map[string]interface{}, len(environment.Variables))
+	for i, variable := range environment.Variables {
+		variables[variable.Key] = map[string]interface{}{
+			"position":    i,
+			"value":       variable.Value,
+			"type":        variable.Type,
+			"description": variable.Description,
+			"enabled":     variable.Enabled,
+		}
+	}
+
+	return toJSONB(map[string]interface{}{
+		"name":      environment.Name,
+		"variables": variables,
+	})
+}
+
+// toJSONB normalizes a value through JSON so snapshots compare the same way
+// they are stored
+func toJSONB(value interface{}) models.JSONB {
+	result := models.JSONB{}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return result
+	}
+	_ = json.Unmarshal(data, &result)
+	return result
+}
+
+// diffSnapshots returns the fields that differ between two snapshots as
+// {"field": {"before": ..., "after": ...}}. Nested objects are flattened into
+// dotted paths such as "variables.API_URL".
+func diffSnapshots(before, after models.JSONB) models.JSONB {
+	changes := models.JSONB{}
+	diffMaps("", map[string]interface{}(before), map[string]interface{}(after), changes)
+	return changes
+}
+
+func diffMaps(prefix string, before, after map[string]interface{}, changes models.JSONB) {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+
+	for key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		oldValue, hadOld := before[key]
+		newValue, hasNew := after[key]
+
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			diffMaps(path, oldMap, newMap, changes)
+			continue
+		}
+
+		if hadOld && hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		changes[path] = map[string]interface{}{
+			"before": oldValue,
+			"after":  newValue,
+		}
+	}
+}
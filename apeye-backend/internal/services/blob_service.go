@@ -0,0 +1,186 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/config"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBlobNotFound = errors.New("file not found")
+	ErrBlobTooLarge = errors.New("file is too large")
+)
+
+// BlobService stores the files that binary request bodies reference. The
+// metadata is in the database and the content in a directory on disk, so
+// neither uploads nor requests hold a file in memory.
+type BlobService struct {
+	blobRepo         *repository.BlobRepository
+	workspaceService *WorkspaceService
+	dir              string
+	maxSize          int64
+}
+
+func NewBlobService(blobRepo *repository.BlobRepository, workspaceService *WorkspaceService, blobConfig config.BlobConfig) *BlobService {
+	return &BlobService{
+		blobRepo:         blobRepo,
+		workspaceService: workspaceService,
+		dir:              blobConfig.Dir,
+		maxSize:          blobConfig.MaxSize,
+	}
+}
+
+// ListBlobs returns the files uploaded to a workspace
+func (s *BlobService) ListBlobs(userID string, workspaceID string) ([]models.Blob, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.blobRepo.FindByWorkspaceID(workspace.ID)
+}
+
+// UploadBlob streams content to disk and records it in the workspace
+func (s *BlobService) UploadBlob(userID string, workspaceID string, name string, contentType string, content io.Reader) (*models.Blob, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create file storage: %w", err)
+	}
+	temp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	defer os.Remove(temp.Name()) // no-op once renamed
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(temp, hash), io.LimitReader(content, s.maxSize+1))
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	if size > s.maxSize {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrBlobTooLarge, s.maxSize)
+	}
+
+	blob := &models.Blob{
+		ID:          uuid.New(),
+		WorkspaceID: workspace.ID,
+		UserID:      userID,
+		Name:        filepath.Base(strings.TrimSpace(name)),
+		ContentType: strings.TrimSpace(contentType),
+		Size:        size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+	}
+	if blob.Name == "." || blob.Name == "/" {
+		blob.Name = "file"
+	}
+	if err := os.Rename(temp.Name(), s.path(blob.ID)); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	if err := s.blobRepo.Create(blob); err != nil {
+		os.Remove(s.path(blob.ID))
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+// DeleteBlob removes a file from a workspace. Saved requests that reference
+// it fail until they are given another file.
+func (s *BlobService) DeleteBlob(userID string, workspaceID string, blobID string) error {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	blob, err := s.findBlob(blobID)
+	if err != nil {
+		return err
+	}
+	if blob.WorkspaceID != workspace.ID {
+		return ErrBlobNotFound
+	}
+
+	if err := s.blobRepo.Delete(blob.ID); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(blob.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// AttachBody lets httpclient send the blob a binary body references, after
+// checking the user can read the blob's workspace. Bodies without a blob are
+// left alone.
+func (s *BlobService) AttachBody(userID string, body *httpclient.Body) error {
+	if body.Type != "binary" || body.File == nil || body.File.BlobID == "" {
+		return nil
+	}
+
+	blob, err := s.findBlob(body.File.BlobID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.workspaceService.Authorize(userID, blob.WorkspaceID, models.RoleViewer); err != nil {
+		return err
+	}
+
+	file := *body.File
+	if file.Name == "" {
+		file.Name = blob.Name
+	}
+	if file.ContentType == "" {
+		file.ContentType = blob.ContentType
+	}
+	path := s.path(blob.ID)
+	file.Open = func() (io.ReadCloser, int64, error) {
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, ErrBlobNotFound
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		return f, blob.Size, nil
+	}
+	body.File = &file
+	return nil
+}
+
+func (s *BlobService) findBlob(blobID string) (*models.Blob, error) {
+	id, err := uuid.Parse(blobID)
+	if err != nil {
+		return nil, ErrBlobNotFound
+	}
+	blob, err := s.blobRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if blob == nil {
+		return nil, ErrBlobNotFound
+	}
+	return blob, nil
+}
+
+// path is where a blob's content is stored
+func (s *BlobService) path(id uuid.UUID) string {
+	return filepath.Join(s.dir, id.String())
+}
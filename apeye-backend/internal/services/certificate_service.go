@@ -0,0 +1,151 @@
+package services
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/secrets"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCertificateNotFound = errors.New("certificate not found")
+	ErrInvalidCertificate  = errors.New("invalid certificate")
+)
+
+type CertificateService struct {
+	certificateRepo *repository.CertificateRepository
+	keyring         *secrets.Keyring
+}
+
+func NewCertificateService(certificateRepo *repository.CertificateRepository, keyring *secrets.Keyring) *CertificateService {
+	return &CertificateService{
+		certificateRepo: certificateRepo,
+		keyring:         keyring,
+	}
+}
+
+type CreateCertificateInput struct {
+	Name        string `json:"name"`
+	HostPattern string `json:"hostPattern" binding:"required"`
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"privateKey"`
+	CA          string `json:"ca"`
+}
+
+// ListCertificates returns the user's client certificates, without private keys
+func (s *CertificateService) ListCertificates(userID string) ([]models.ClientCertificate, error) {
+	return s.certificateRepo.FindByUserID(userID)
+}
+
+// CreateCertificate validates and stores a client certificate, encrypting its private key
+func (s *CertificateService) CreateCertificate(userID string, input CreateCertificateInput) (*models.ClientCertificate, error) {
+	cert := &models.ClientCertificate{
+		UserID:      userID,
+		Name:        strings.TrimSpace(input.Name),
+		HostPattern: strings.ToLower(strings.TrimSpace(input.HostPattern)),
+		Certificate: strings.TrimSpace(input.Certificate),
+		CA:          strings.TrimSpace(input.CA),
+	}
+	if cert.Name == "" {
+		cert.Name = cert.HostPattern
+	}
+
+	err := httpclient.ValidateCertificate(httpclient.ClientCertificate{
+		HostPattern: cert.HostPattern,
+		Certificate: cert.Certificate,
+		PrivateKey:  input.PrivateKey,
+		CA:          cert.CA,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+
+	if cert.Certificate != "" {
+		if block, _ := pem.Decode([]byte(cert.Certificate)); block != nil {
+			if parsed, err := x509.ParseCertificate(block.Bytes); err == nil {
+				cert.Subject = parsed.Subject.String()
+				cert.ExpiresAt = &parsed.NotAfter
+			}
+		}
+
+		dataKey, wrapped, err := s.keyring.GenerateDataKey()
+		if err != nil {
+			return nil, err
+		}
+		if cert.PrivateKey, err = secrets.Encrypt(dataKey, strings.TrimSpace(input.PrivateKey)); err != nil {
+			return nil, err
+		}
+		cert.DataKey = wrapped
+	}
+
+	if err := s.certificateRepo.Create(cert); err != nil {
+		return nil, err
+	}
+
+	return cert, nil
+}
+
+// DeleteCertificate deletes one of the user's client certificates
+func (s *CertificateService) DeleteCertificate(userID, certificateID string) error {
+	cert, err := s.getOwnedCertificate(userID, certificateID)
+	if err != nil {
+		return err
+	}
+
+	return s.certificateRepo.Delete(cert.ID)
+}
+
+// RequestCertificates returns the user's certificates, decrypted, for httpclient
+func (s *CertificateService) RequestCertificates(userID string) ([]httpclient.ClientCertificate, error) {
+	certs, err := s.certificateRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]httpclient.ClientCertificate, 0, len(certs))
+	for _, cert := range certs {
+		var privateKey string
+		if cert.PrivateKey != "" {
+			dataKey, err := s.keyring.UnwrapDataKey(cert.DataKey)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+			}
+			if privateKey, err = secrets.Decrypt(dataKey, cert.PrivateKey); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+			}
+		}
+
+		result = append(result, httpclient.ClientCertificate{
+			HostPattern: cert.HostPattern,
+			Certificate: cert.Certificate,
+			PrivateKey:  privateKey,
+			CA:          cert.CA,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *CertificateService) getOwnedCertificate(userID, certificateID string) (*models.ClientCertificate, error) {
+	id, err := uuid.Parse(certificateID)
+	if err != nil {
+		return nil, ErrCertificateNotFound
+	}
+
+	cert, err := s.certificateRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil || cert.UserID != userID {
+		return nil, ErrCertificateNotFound
+	}
+
+	return cert, nil
+}
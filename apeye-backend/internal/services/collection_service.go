@@ -10,24 +10,31 @@ import (
 
 var (
 	ErrCollectionNotFound = errors.New("collection not found")
+	ErrRequestNotFound    = errors.New("request not found")
 	ErrUnauthorized       = errors.New("unauthorized access")
 )
 
 type CollectionService struct {
-	collectionRepo *repository.CollectionRepository
-	workspaceRepo  *repository.WorkspaceRepository
-	requestRepo    *repository.RequestRepository
+	collectionRepo   *repository.CollectionRepository
+	workspaceService *WorkspaceService
+	requestRepo      *repository.RequestRepository
+	auditService     *AuditService
+	revisionService  *RevisionService
 }
 
 func NewCollectionService(
 	collectionRepo *repository.CollectionRepository,
-	workspaceRepo *repository.WorkspaceRepository,
+	workspaceService *WorkspaceService,
 	requestRepo *repository.RequestRepository,
+	auditService *AuditService,
+	revisionService *RevisionService,
 ) *CollectionService {
 	return &CollectionService{
-		collectionRepo: collectionRepo,
-		workspaceRepo:  workspaceRepo,
-		requestRepo:    requestRepo,
+		collectionRepo:   collectionRepo,
+		workspaceService: workspaceService,
+		requestRepo:      requestRepo,
+		auditService:     auditService,
+		revisionService:  revisionService,
 	}
 }
 
@@ -53,10 +60,20 @@ type SaveRequestInput struct {
 	Body         map[string]interface{} `json:"body"`
 }
 
-// GetUserCollections returns all collections for a user
-func (s *CollectionService) GetUserCollections(userID string) ([]models.Collection, error) {
-	// Get user's default workspace
-	workspace, err := s.workspaceRepo.FindDefaultByUserID(userID)
+type UpdateRequestInput struct {
+	Name    string                 `json:"name"`
+	Method  string                 `json:"method"`
+	URL     string                 `json:"url"`
+	Headers map[string]interface{} `json:"headers"`
+	Params  map[string]interface{} `json:"params"`
+	Auth    map[string]interface{} `json:"auth"`
+	Body    map[string]interface{} `json:"body"`
+}
+
+// GetUserCollections returns all collections in a workspace the user can view
+// (the user's default workspace when workspaceID is empty)
+func (s *CollectionService) GetUserCollections(userID string, workspaceID string) ([]models.Collection, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
 	if err != nil {
 		return nil, err
 	}
@@ -72,40 +89,14 @@ func (s *CollectionService) GetUserCollections(userID string) ([]models.Collecti
 
 // CreateCollection creates a new collection
 func (s *CollectionService) CreateCollection(userID string, input CreateCollectionInput) (*models.Collection, error) {
-	var workspaceID uuid.UUID
-	var err error
-
-	if input.WorkspaceID == "" || 
-	   input.WorkspaceID == "default" || 
-	   input.WorkspaceID == "default-workspace-id" {
-		// Get or create the user's default workspace
-		workspace, err := s.workspaceRepo.FindDefaultByUserID(userID)
-		if err != nil {
-			return nil, err
-		}
-		workspaceID = workspace.ID
-	} else {
-		// Parse provided workspace ID
-		workspaceID, err = uuid.Parse(input.WorkspaceID)
-		if err != nil {
-			return nil, errors.New("invalid workspace ID format")
-		}
-
-		// Verify workspace exists and belongs to user
-		workspace, err := s.workspaceRepo.FindByID(workspaceID)
-		if err != nil {
-			return nil, err
-		}
-		if workspace == nil {
-			return nil, errors.New("workspace not found")
-		}
-		if workspace.UserID != userID {
-			return nil, ErrUnauthorized
-		}
+	// Creating collections requires edit access to the workspace
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, input.WorkspaceID, models.RoleEditor)
+	if err != nil {
+		return nil, err
 	}
 
 	collection := &models.Collection{
-		WorkspaceID: workspaceID,
+		WorkspaceID: workspace.ID,
 		Name:        input.Name,
 		Description: input.Description,
 	}
@@ -114,11 +105,21 @@ func (s *CollectionService) CreateCollection(userID string, input CreateCollecti
 		return nil, err
 	}
 
+	s.auditService.Record(collection.WorkspaceID, userID, models.AuditCreate, models.AuditEntityCollection,
+		collection.ID, collection.Name, nil, collectionSnapshot(collection))
+	s.revisionService.Record(models.RevisionCollection, collection.ID, collection.WorkspaceID,
+		userID, nil, collectionSnapshot(collection))
+
 	return collection, nil
 }
 
 // GetCollection returns a collection by ID
 func (s *CollectionService) GetCollection(userID string, collectionID string) (*models.Collection, error) {
+	return s.authorizeCollection(userID, collectionID, models.RoleViewer)
+}
+
+// authorizeCollection loads a collection and checks the user's role in its workspace
+func (s *CollectionService) authorizeCollection(userID string, collectionID string, required models.WorkspaceRole) (*models.Collection, error) {
 	id, err := uuid.Parse(collectionID)
 	if err != nil {
 		return nil, errors.New("invalid collection ID")
@@ -132,25 +133,23 @@ func (s *CollectionService) GetCollection(userID string, collectionID string) (*
 		return nil, ErrCollectionNotFound
 	}
 
-	// Verify user owns the workspace
-	workspace, err := s.workspaceRepo.FindByID(collection.WorkspaceID)
-	if err != nil {
+	// Verify user is a member of the workspace with the required role
+	if _, err := s.workspaceService.Authorize(userID, collection.WorkspaceID, required); err != nil {
 		return nil, err
 	}
-	if workspace.UserID != userID {
-		return nil, ErrUnauthorized
-	}
 
 	return collection, nil
 }
 
 // UpdateCollection updates a collection
 func (s *CollectionService) UpdateCollection(userID string, collectionID string, input UpdateCollectionInput) (*models.Collection, error) {
-	collection, err := s.GetCollection(userID, collectionID)
+	collection, err := s.authorizeCollection(userID, collectionID, models.RoleEditor)
 	if err != nil {
 		return nil, err
 	}
 
+	before := collectionSnapshot(collection)
+
 	if input.Name != "" {
 		collection.Name = input.Name
 	}
@@ -160,18 +159,29 @@ func (s *CollectionService) UpdateCollection(userID string, collectionID string,
 		return nil, err
 	}
 
+	s.auditService.Record(collection.WorkspaceID, userID, models.AuditUpdate, models.AuditEntityCollection,
+		collection.ID, collection.Name, before, collectionSnapshot(collection))
+	s.revisionService.Record(models.RevisionCollection, collection.ID, collection.WorkspaceID,
+		userID, before, collectionSnapshot(collection))
+
 	return collection, nil
 }
 
 // DeleteCollection deletes a collection
 func (s *CollectionService) DeleteCollection(userID string, collectionID string) error {
-	_, err := s.GetCollection(userID, collectionID)
+	collection, err := s.authorizeCollection(userID, collectionID, models.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	id, _ := uuid.Parse(collectionID)
-	return s.collectionRepo.Delete(id)
+	if err := s.collectionRepo.Delete(collection.ID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(collection.WorkspaceID, userID, models.AuditDelete, models.AuditEntityCollection,
+		collection.ID, collection.Name, collectionSnapshot(collection), nil)
+
+	return nil
 }
 
 // SaveRequest saves a request to a collection
@@ -181,8 +191,8 @@ func (s *CollectionService) SaveRequest(userID string, input SaveRequestInput) (
 		return nil, errors.New("invalid collection ID")
 	}
 
-	// Verify collection belongs to user
-	collection, err := s.GetCollection(userID, input.CollectionID)
+	// Verify user can edit the collection
+	collection, err := s.authorizeCollection(userID, input.CollectionID, models.RoleEditor)
 	if err != nil {
 		return nil, err
 	}
@@ -223,6 +233,11 @@ func (s *CollectionService) SaveRequest(userID string, input SaveRequestInput) (
 		return nil, err
 	}
 
+	s.auditService.Record(collection.WorkspaceID, userID, models.AuditCreate, models.AuditEntityRequest,
+		request.ID, request.Name, nil, requestSnapshot(request))
+	s.revisionService.Record(models.RevisionRequest, request.ID, collection.WorkspaceID,
+		userID, nil, requestSnapshot(request))
+
 	// Reload with collection data
 	request.Collection = *collection
 
@@ -242,14 +257,234 @@ func (s *CollectionService) DeleteRequest(userID string, requestID string) error
 		return err
 	}
 	if request == nil {
-		return errors.New("request not found")
+		return ErrRequestNotFound
 	}
 
-	// Verify user owns the collection
-	_, err = s.GetCollection(userID, request.CollectionID.String())
+	// Verify user can edit the collection
+	collection, err := s.authorizeCollection(userID, request.CollectionID.String(), models.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	return s.requestRepo.Delete(id)
-}
\ No newline at end of file
+	if err := s.requestRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.auditService.Record(collection.WorkspaceID, userID, models.AuditDelete, models.AuditEntityRequest,
+		request.ID, request.Name, requestSnapshot(request), nil)
+
+	return nil
+}
+
+// authorizeRequest loads a saved request and checks the user's role in its workspace
+func (s *CollectionService) authorizeRequest(userID string, requestID string, required models.WorkspaceRole) (*models.Request, *models.Collection, error) {
+	id, err := uuid.Parse(requestID)
+	if err != nil {
+		return nil, nil, errors.New("invalid request ID")
+	}
+
+	request, err := s.requestRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if request == nil {
+		return nil, nil, ErrRequestNotFound
+	}
+
+	collection, err := s.authorizeCollection(userID, request.CollectionID.String(), required)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return request, collection, nil
+}
+
+// UpdateRequest updates a saved request, keeping the previous definition as a revision
+func (s *CollectionService) UpdateRequest(userID string, requestID string, input UpdateRequestInput) (*models.Request, error) {
+	request, collection, err := s.authorizeRequest(userID, requestID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	before := requestSnapshot(request)
+
+	if input.Name != "" {
+		request.Name = input.Name
+	}
+	if input.Method != "" {
+		request.Method = models.HTTPMethod(input.Method)
+	}
+	if input.URL != "" {
+		request.URL = input.URL
+	}
+	if input.Headers != nil {
+		request.Headers = models.JSONB(input.Headers)
+	}
+	if input.Params != nil {
+		request.Params = models.JSONB(input.Params)
+	}
+	if input.Auth != nil {
+		request.Auth = models.JSONB(input.Auth)
+	}
+	if input.Body != nil {
+		request.Body = models.JSONB(input.Body)
+	}
+
+	if err := s.requestRepo.Update(request); err != nil {
+		return nil, err
+	}
+
+	s.recordRequestChange(userID, collection, request, before)
+
+	return request, nil
+}
+
+// recordRequestChange writes the audit event and revision for an updated request
+func (s *CollectionService) recordRequestChange(userID string, collection *models.Collection, request *models.Request, before models.JSONB) {
+	after := requestSnapshot(request)
+	s.auditService.Record(collection.WorkspaceID, userID, models.AuditUpdate, models.AuditEntityRequest,
+		request.ID, request.Name, before, after)
+	s.revisionService.Record(models.RevisionRequest, request.ID, collection.WorkspaceID,
+		userID, before, after)
+}
+
+// ListCollectionRevisions returns the version history of a collection
+func (s *CollectionService) ListCollectionRevisions(userID string, collectionID string) ([]models.Revision, error) {
+	collection, err := s.authorizeCollection(userID, collectionID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisionService.List(models.RevisionCollection, collection.ID)
+}
+
+// GetCollectionRevision returns a single version of a collection
+func (s *CollectionService) GetCollectionRevision(userID string, collectionID string, version int) (*models.Revision, error) {
+	collection, err := s.authorizeCollection(userID, collectionID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisionService.Get(models.RevisionCollection, collection.ID, version)
+}
+
+// DiffCollectionRevisions compares two versions of a collection
+func (s *CollectionService) DiffCollectionRevisions(userID string, collectionID string, from, to int) (*RevisionDiff, error) {
+	collection, err := s.authorizeCollection(userID, collectionID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisionService.Diff(models.RevisionCollection, collection.ID, from, to)
+}
+
+// RestoreCollectionRevision brings a collection back to an older version.
+// The restore itself is recorded as a new revision.
+func (s *CollectionService) RestoreCollectionRevision(userID string, collectionID string, version int) (*models.Collection, error) {
+	collection, err := s.authorizeCollection(userID, collectionID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.revisionService.Get(models.RevisionCollection, collection.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	before := collectionSnapshot(collection)
+
+	if name, ok := revision.Snapshot["name"].(string); ok && name != "" {
+		collection.Name = name
+	}
+	if description, ok := revision.Snapshot["description"].(string); ok {
+		collection.Description = description
+	}
+
+	if err := s.collectionRepo.Update(collection); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(collection.WorkspaceID, userID, models.AuditUpdate, models.AuditEntityCollection,
+		collection.ID, collection.Name, before, collectionSnapshot(collection))
+	s.revisionService.Record(models.RevisionCollection, collection.ID, collection.WorkspaceID,
+		userID, before, collectionSnapshot(collection))
+
+	return collection, nil
+}
+
+// ListRequestRevisions returns the version history of a saved request
+func (s *CollectionService) ListRequestRevisions(userID string, requestID string) ([]models.Revision, error) {
+	request, _, err := s.authorizeRequest(userID, requestID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisionService.List(models.RevisionRequest, request.ID)
+}
+
+// GetRequestRevision returns a single version of a saved request
+func (s *CollectionService) GetRequestRevision(userID string, requestID string, version int) (*models.Revision, error) {
+	request, _, err := s.authorizeRequest(userID, requestID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisionService.Get(models.RevisionRequest, request.ID, version)
+}
+
+// DiffRequestRevisions compares two versions of a saved request
+func (s *CollectionService) DiffRequestRevisions(userID string, requestID string, from, to int) (*RevisionDiff, error) {
+	request, _, err := s.authorizeRequest(userID, requestID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisionService.Diff(models.RevisionRequest, request.ID, from, to)
+}
+
+// RestoreRequestRevision brings a saved request back to an older version.
+// The request stays in its current collection and the restore is recorded as a new revision.
+func (s *CollectionService) RestoreRequestRevision(userID string, requestID string, version int) (*models.Request, error) {
+	request, collection, err := s.authorizeRequest(userID, requestID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.revisionService.Get(models.RevisionRequest, request.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	before := requestSnapshot(request)
+
+	snapshot := revision.Snapshot
+	if name, ok := snapshot["name"].(string); ok && name != "" {
+		request.Name = name
+	}
+	if method, ok := snapshot["method"].(string); ok && method != "" {
+		request.Method = models.HTTPMethod(method)
+	}
+	if url, ok := snapshot["url"].(string); ok && url != "" {
+		request.URL = url
+	}
+	request.Headers = snapshotObject(snapshot, "headers")
+	request.Params = snapshotObject(snapshot, "params")
+	request.Auth = snapshotObject(snapshot, "auth")
+	request.Body = snapshotObject(snapshot, "body")
+
+	if err := s.requestRepo.Update(request); err != nil {
+		return nil, err
+	}
+
+	s.recordRequestChange(userID, collection, request, before)
+
+	return request, nil
+}
+
+// snapshotObject reads a nested object from a snapshot, defaulting to empty
+func snapshotObject(snapshot models.JSONB, key string) models.JSONB {
+	if value, ok := snapshot[key].(map[string]interface{}); ok {
+		return models.JSONB(value)
+	}
+	return models.JSONB{}
+}
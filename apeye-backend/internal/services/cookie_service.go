@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCookieNotFound = errors.New("cookie not found")
+	ErrInvalidCookie  = errors.New("invalid cookie: name and domain are required")
+)
+
+type CookieService struct {
+	cookieRepo       *repository.CookieRepository
+	workspaceService *WorkspaceService
+}
+
+func NewCookieService(cookieRepo *repository.CookieRepository, workspaceService *WorkspaceService) *CookieService {
+	return &CookieService{
+		cookieRepo:       cookieRepo,
+		workspaceService: workspaceService,
+	}
+}
+
+type CookieInput struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain"`
+	Path     string     `json:"path"`
+	Expires  *time.Time `json:"expires"`
+	Secure   bool       `json:"secure"`
+	HttpOnly bool       `json:"httpOnly"`
+	HostOnly bool       `json:"hostOnly"`
+	SameSite string     `json:"sameSite"`
+}
+
+// ListCookies returns the cookies in a workspace's jar, optionally for one domain
+func (s *CookieService) ListCookies(userID string, workspaceID string, domain string) ([]models.Cookie, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cookieRepo.FindByWorkspaceID(workspace.ID, normalizeCookieDomain(domain))
+}
+
+// SetCookie adds a cookie to a workspace's jar, replacing one with the same
+// domain, path and name
+func (s *CookieService) SetCookie(userID string, workspaceID string, input CookieInput) (*models.Cookie, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	cookie := &models.Cookie{WorkspaceID: workspace.ID}
+	if err := applyCookieInput(cookie, input); err != nil {
+		return nil, err
+	}
+	if err := s.cookieRepo.Upsert(cookie); err != nil {
+		return nil, err
+	}
+
+	return cookie, nil
+}
+
+// UpdateCookie edits a cookie in a workspace's jar
+func (s *CookieService) UpdateCookie(userID string, workspaceID string, cookieID string, input CookieInput) (*models.Cookie, error) {
+	cookie, err := s.getCookie(userID, workspaceID, cookieID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyCookieInput(cookie, input); err != nil {
+		return nil, err
+	}
+	if err := s.cookieRepo.Update(cookie); err != nil {
+		return nil, err
+	}
+
+	return cookie, nil
+}
+
+// DeleteCookie removes a cookie from a workspace's jar
+func (s *CookieService) DeleteCookie(userID string, workspaceID string, cookieID string) error {
+	cookie, err := s.getCookie(userID, workspaceID, cookieID)
+	if err != nil {
+		return err
+	}
+
+	return s.cookieRepo.Delete(cookie.ID)
+}
+
+// ClearCookies empties a workspace's jar, or only the cookies of one domain
+func (s *CookieService) ClearCookies(userID string, workspaceID string, domain string) error {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	return s.cookieRepo.DeleteByWorkspaceID(workspace.ID, normalizeCookieDomain(domain))
+}
+
+// Jar loads a workspace's cookies into a jar for httpclient. Callers must
+// have authorized access to the workspace.
+func (s *CookieService) Jar(workspaceID uuid.UUID) (*httpclient.CookieJar, error) {
+	cookies, err := s.cookieRepo.FindByWorkspaceID(workspaceID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make([]httpclient.Cookie, len(cookies))
+	for i, cookie := range cookies {
+		stored[i] = httpclient.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expires:  cookie.Expires,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			HostOnly: cookie.HostOnly,
+			SameSite: cookie.SameSite,
+		}
+	}
+	return httpclient.NewCookieJar(stored), nil
+}
+
+// CanSaveJar reports whether the user's requests may change a workspace's
+// jar. Viewers send its cookies but don't save the ones responses set.
+func (s *CookieService) CanSaveJar(userID string, workspaceID uuid.UUID) (bool, error) {
+	_, err := s.workspaceService.Authorize(userID, workspaceID, models.RoleEditor)
+	if errors.Is(err, ErrInsufficientRole) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SaveJar persists the cookies a request set or expired. Only changes are
+// written so concurrent requests in the workspace don't undo each other.
+func (s *CookieService) SaveJar(workspaceID uuid.UUID, jar *httpclient.CookieJar) error {
+	set, removed := jar.Changes()
+	for _, cookie := range set {
+		err := s.cookieRepo.Upsert(&models.Cookie{
+			WorkspaceID: workspaceID,
+			Name:        cookie.Name,
+			Value:       cookie.Value,
+			Domain:      cookie.Domain,
+			Path:        cookie.Path,
+			Expires:     cookie.Expires,
+			Secure:      cookie.Secure,
+			HttpOnly:    cookie.HttpOnly,
+			HostOnly:    cookie.HostOnly,
+			SameSite:    cookie.SameSite,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, cookie := range removed {
+		if err := s.cookieRepo.DeleteByKey(workspaceID, cookie.Domain, cookie.Path, cookie.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *CookieService) getCookie(userID string, workspaceID string, cookieID string) (*models.Cookie, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(cookieID)
+	if err != nil {
+		return nil, ErrCookieNotFound
+	}
+	cookie, err := s.cookieRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cookie == nil || cookie.WorkspaceID != workspace.ID {
+		return nil, ErrCookieNotFound
+	}
+
+	return cookie, nil
+}
+
+func applyCookieInput(cookie *models.Cookie, input CookieInput) error {
+	name := strings.TrimSpace(input.Name)
+	domain := normalizeCookieDomain(input.Domain)
+	if name == "" || domain == "" {
+		return ErrInvalidCookie
+	}
+
+	path := strings.TrimSpace(input.Path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/"
+	}
+
+	cookie.Name = name
+	cookie.Value = input.Value
+	cookie.Domain = domain
+	cookie.Path = path
+	cookie.Expires = input.Expires
+	cookie.Secure = input.Secure
+	cookie.HttpOnly = input.HttpOnly
+	cookie.HostOnly = input.HostOnly
+	cookie.SameSite = input.SameSite
+	return nil
+}
+
+func normalizeCookieDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
@@ -1,47 +1,61 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
 
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/dotenv"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/secrets"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrEnvironmentNotFound = errors.New("environment not found")
+	ErrEnvironmentNotFound    = errors.New("environment not found")
+	ErrInvalidEnvironmentFile = errors.New("invalid environment file")
 )
 
 type EnvironmentService struct {
-	environmentRepo *repository.EnvironmentRepository
-	workspaceRepo   *repository.WorkspaceRepository
+	environmentRepo  *repository.EnvironmentRepository
+	workspaceService *WorkspaceService
+	auditService     *AuditService
+	keyring          *secrets.Keyring
 }
 
 func NewEnvironmentService(
 	environmentRepo *repository.EnvironmentRepository,
-	workspaceRepo *repository.WorkspaceRepository,
+	workspaceService *WorkspaceService,
+	auditService *AuditService,
+	keyring *secrets.Keyring,
 ) *EnvironmentService {
 	return &EnvironmentService{
-		environmentRepo: environmentRepo,
-		workspaceRepo:   workspaceRepo,
+		environmentRepo:  environmentRepo,
+		workspaceService: workspaceService,
+		auditService:     auditService,
+		keyring:          keyring,
 	}
 }
 
 type CreateEnvironmentInput struct {
 	WorkspaceID string            `json:"workspace_id"`
 	Name        string            `json:"name" binding:"required"`
-	Variables   map[string]string `json:"variables"`
+	Variables   []models.Variable `json:"variables"`
 }
 
 type UpdateEnvironmentInput struct {
 	Name      string            `json:"name"`
-	Variables map[string]string `json:"variables"`
+	Variables []models.Variable `json:"variables"` // nil keeps the current variables
 }
 
-// GetUserEnvironments returns all environments for a user's default workspace
-func (s *EnvironmentService) GetUserEnvironments(userID string) ([]models.Environment, error) {
-	// Get user's default workspace
-	workspace, err := s.workspaceRepo.FindDefaultByUserID(userID)
+// GetUserEnvironments returns all environments in a workspace the user can view
+// (the user's default workspace when workspaceID is empty)
+func (s *EnvironmentService) GetUserEnvironments(userID string, workspaceID string) ([]models.Environment, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
 	if err != nil {
 		return nil, err
 	}
@@ -52,62 +66,54 @@ func (s *EnvironmentService) GetUserEnvironments(userID string) ([]models.Enviro
 		return nil, err
 	}
 
+	for i := range environments {
+		environments[i].Variables = maskVariables(environments[i].Variables)
+	}
+
 	return environments, nil
 }
 
 // CreateEnvironment creates a new environment
 func (s *EnvironmentService) CreateEnvironment(userID string, input CreateEnvironmentInput) (*models.Environment, error) {
-	var workspaceID uuid.UUID
-	var err error
-
-	if input.WorkspaceID == "" || input.WorkspaceID == "default" {
-		// Get or create the user's default workspace
-		workspace, err := s.workspaceRepo.FindDefaultByUserID(userID)
-		if err != nil {
-			return nil, err
-		}
-		workspaceID = workspace.ID
-	} else {
-		// Parse provided workspace ID
-		workspaceID, err = uuid.Parse(input.WorkspaceID)
-		if err != nil {
-			return nil, errors.New("invalid workspace ID format")
-		}
-
-		// Verify workspace exists and belongs to user
-		workspace, err := s.workspaceRepo.FindByID(workspaceID)
-		if err != nil {
-			return nil, err
-		}
-		if workspace == nil {
-			return nil, errors.New("workspace not found")
-		}
-		if workspace.UserID != userID {
-			return nil, ErrUnauthorized
-		}
-	}
-
-	// Convert map[string]string to JSONB
-	variables := models.JSONB{}
-	for k, v := range input.Variables {
-		variables[k] = v
+	// Creating environments requires edit access to the workspace
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, input.WorkspaceID, models.RoleEditor)
+	if err != nil {
+		return nil, err
 	}
 
 	environment := &models.Environment{
-		WorkspaceID: workspaceID,
+		WorkspaceID: workspace.ID,
 		Name:        input.Name,
-		Variables:   variables,
+	}
+
+	if environment.Variables, err = sealVariables(s.keyring, nil, &environment.DataKey, input.Variables); err != nil {
+		return nil, err
 	}
 
 	if err := s.environmentRepo.Create(environment); err != nil {
 		return nil, err
 	}
 
+	s.auditService.Record(environment.WorkspaceID, userID, models.AuditCreate, models.AuditEntityEnvironment,
+		environment.ID, environment.Name, nil, environmentSnapshot(environment))
+
+	environment.Variables = maskVariables(environment.Variables)
 	return environment, nil
 }
 
-// GetEnvironment returns an environment by ID
+// GetEnvironment returns an environment by ID with secret values masked
 func (s *EnvironmentService) GetEnvironment(userID string, environmentID string) (*models.Environment, error) {
+	environment, err := s.authorizeEnvironment(userID, environmentID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	environment.Variables = maskVariables(environment.Variables)
+	return environment, nil
+}
+
+// authorizeEnvironment loads an environment and checks the user's role in its workspace
+func (s *EnvironmentService) authorizeEnvironment(userID string, environmentID string, required models.WorkspaceRole) (*models.Environment, error) {
 	id, err := uuid.Parse(environmentID)
 	if err != nil {
 		return nil, errors.New("invalid environment ID")
@@ -121,52 +127,449 @@ func (s *EnvironmentService) GetEnvironment(userID string, environmentID string)
 		return nil, ErrEnvironmentNotFound
 	}
 
-	// Verify user owns the workspace
-	workspace, err := s.workspaceRepo.FindByID(environment.WorkspaceID)
-	if err != nil {
+	// Verify user is a member of the workspace with the required role
+	if _, err := s.workspaceService.Authorize(userID, environment.WorkspaceID, required); err != nil {
 		return nil, err
 	}
-	if workspace.UserID != userID {
-		return nil, ErrUnauthorized
-	}
 
 	return environment, nil
 }
 
 // UpdateEnvironment updates an environment
 func (s *EnvironmentService) UpdateEnvironment(userID string, environmentID string, input UpdateEnvironmentInput) (*models.Environment, error) {
-	environment, err := s.GetEnvironment(userID, environmentID)
+	environment, err := s.authorizeEnvironment(userID, environmentID, models.RoleEditor)
 	if err != nil {
 		return nil, err
 	}
 
+	before := environmentSnapshot(environment)
+
 	if input.Name != "" {
 		environment.Name = input.Name
 	}
 
 	if input.Variables != nil {
-		// Convert map[string]string to JSONB
-		variables := models.JSONB{}
-		for k, v := range input.Variables {
-			variables[k] = v
+		if environment.Variables, err = sealVariables(s.keyring, environment.Variables, &environment.DataKey, input.Variables); err != nil {
+			return nil, err
 		}
-		environment.Variables = variables
 	}
 
 	if err := s.environmentRepo.Update(environment); err != nil {
 		return nil, err
 	}
 
+	s.auditService.Record(environment.WorkspaceID, userID, models.AuditUpdate, models.AuditEntityEnvironment,
+		environment.ID, environment.Name, before, environmentSnapshot(environment))
+
+	environment.Variables = maskVariables(environment.Variables)
 	return environment, nil
 }
 
 // DeleteEnvironment deletes an environment
 func (s *EnvironmentService) DeleteEnvironment(userID string, environmentID string) error {
-	_, err := s.GetEnvironment(userID, environmentID)
+	environment, err := s.authorizeEnvironment(userID, environmentID, models.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	id, _ := uuid.Parse(environmentID)
-	return s.environmentRepo.Delete(id)
+	if err := s.environmentRepo.Delete(environment.ID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(environment.WorkspaceID, userID, models.AuditDelete, models.AuditEntityEnvironment,
+		environment.ID, environment.Name, environmentSnapshot(environment), nil)
+
+	return nil
+}
+
+// environmentLayer returns the enabled variables of an environment with secrets
+// decrypted, for executing a request
+func (s *EnvironmentService) environmentLayer(userID string, environmentID string) (*models.Environment, httpclient.VariableLayer, error) {
+	environment, err := s.authorizeEnvironment(userID, environmentID, models.RoleViewer)
+	if err != nil {
+		return nil, httpclient.VariableLayer{}, err
+	}
+
+	layer, err := variableLayer(httpclient.ScopeEnvironment, s.keyring, environment.Variables, environment.DataKey)
+	if err != nil {
+		return nil, httpclient.VariableLayer{}, err
+	}
+	return environment, layer, nil
+}
+
+// Environment file formats for import and export
+const (
+	EnvironmentFormatDotenv = "dotenv"
+	EnvironmentFormatJSON   = "json"
+)
+
+type CloneEnvironmentInput struct {
+	Name        string `json:"name"`
+	WorkspaceID string `json:"workspace_id"` // defaults to the source environment's workspace
+}
+
+type ImportEnvironmentInput struct {
+	Format  string // dotenv or json
+	Replace bool   // drop variables missing from the file instead of keeping them
+	Data    []byte
+}
+
+// VariableChange describes how one variable differs between two environments.
+// Secret values are masked, only whether they differ is reported.
+type VariableChange struct {
+	Key    string           `json:"key"`
+	Status string           `json:"status"` // added, removed, changed or unchanged
+	Before *models.Variable `json:"before,omitempty"`
+	After  *models.Variable `json:"after,omitempty"`
+}
+
+// EnvironmentDiff compares the variables of two environments key by key
+type EnvironmentDiff struct {
+	From    uuid.UUID        `json:"from"`
+	To      uuid.UUID        `json:"to"`
+	Changes []VariableChange `json:"changes"`
+}
+
+// CloneEnvironment copies an environment with all its variables. Secrets are
+// copied as ciphertext together with their data key, so they are never decrypted,
+// and only for editors of the source environment.
+func (s *EnvironmentService) CloneEnvironment(userID string, environmentID string, input CloneEnvironmentInput) (*models.Environment, error) {
+	source, err := s.authorizeEnvironment(userID, environmentID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceID := source.WorkspaceID
+	if input.WorkspaceID != "" {
+		workspace, err := s.workspaceService.ResolveWorkspace(userID, input.WorkspaceID, models.RoleEditor)
+		if err != nil {
+			return nil, err
+		}
+		workspaceID = workspace.ID
+	} else if _, err := s.workspaceService.Authorize(userID, workspaceID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	name := input.Name
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+
+	variables := make(models.VariableList, len(source.Variables))
+	copy(variables, source.Variables)
+	dataKey := source.DataKey
+
+	// Viewers of the source can't read its secrets, so they get the keys
+	// without values rather than ciphertext they could export from the copy
+	if _, err := s.workspaceService.Authorize(userID, source.WorkspaceID, models.RoleEditor); err != nil {
+		if !errors.Is(err, ErrInsufficientRole) {
+			return nil, err
+		}
+		for i := range variables {
+			if variables[i].Type == models.VariableSecret {
+				variables[i].Value = ""
+			}
+		}
+		dataKey = ""
+	}
+
+	environment := &models.Environment{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Variables:   variables,
+		DataKey:     dataKey,
+	}
+
+	if err := s.environmentRepo.Create(environment); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(environment.WorkspaceID, userID, models.AuditCreate, models.AuditEntityEnvironment,
+		environment.ID, environment.Name, nil, environmentSnapshot(environment))
+
+	environment.Variables = maskVariables(environment.Variables)
+	return environment, nil
+}
+
+// DiffEnvironments compares two environments the user can view, key by key
+func (s *EnvironmentService) DiffEnvironments(userID string, fromID string, toID string) (*EnvironmentDiff, error) {
+	from, err := s.authorizeEnvironment(userID, fromID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.authorizeEnvironment(userID, toID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Secrets are compared by plaintext since each environment has its own data key
+	fromVariables, err := s.decryptVariables(from)
+	if err != nil {
+		return nil, err
+	}
+	toVariables, err := s.decryptVariables(to)
+	if err != nil {
+		return nil, err
+	}
+
+	masked := func(variable models.Variable) *models.Variable {
+		if variable.Type == models.VariableSecret {
+			variable.Value = SecretMask
+		}
+		return &variable
+	}
+
+	changes := []VariableChange{}
+	for _, before := range fromVariables {
+		change := VariableChange{Key: before.Key, Before: masked(before)}
+		if after, ok := toVariables.Find(before.Key); ok {
+			change.After = masked(*after)
+			change.Status = "unchanged"
+			if *after != before {
+				change.Status = "changed"
+			}
+		} else {
+			change.Status = "removed"
+		}
+		changes = append(changes, change)
+	}
+	for _, after := range toVariables {
+		if _, ok := fromVariables.Find(after.Key); !ok {
+			changes = append(changes, VariableChange{Key: after.Key, Status: "added", After: masked(after)})
+		}
+	}
+
+	return &EnvironmentDiff{From: from.ID, To: to.ID, Changes: changes}, nil
+}
+
+// ImportEnvironment loads variables from a dotenv or JSON file. Imported keys
+// replace existing values (keeping the existing type, so secrets stay secret)
+// and new keys are appended.
+func (s *EnvironmentService) ImportEnvironment(userID string, environmentID string, input ImportEnvironmentInput) (*models.Environment, error) {
+	environment, err := s.authorizeEnvironment(userID, environmentID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := parseEnvironmentFile(input.Format, input.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start from the masked current variables so untouched secrets keep their ciphertext
+	merged := models.VariableList{}
+	if !input.Replace {
+		merged = maskVariables(environment.Variables)
+	}
+	for _, variable := range imported {
+		if existing, ok := merged.Find(variable.Key); ok {
+			existing.Value = variable.Value
+			if variable.Type != "" && variable.Type != models.VariableString {
+				existing.Type = variable.Type
+			}
+			if variable.Description != "" {
+				existing.Description = variable.Description
+			}
+			existing.Enabled = variable.Enabled
+			continue
+		}
+		if previous, ok := environment.Variables.Find(variable.Key); ok && (variable.Type == "" || variable.Type == models.VariableString) {
+			variable.Type = previous.Type
+		}
+		merged = append(merged, variable)
+	}
+
+	// Exports leave secret values empty, importing them back keeps the stored secrets
+	for i := range merged {
+		previous, ok := environment.Variables.Find(merged[i].Key)
+		if ok && previous.Type == models.VariableSecret && merged[i].Type == models.VariableSecret && merged[i].Value == "" {
+			merged[i].Value = SecretMask
+		}
+	}
+
+	before := environmentSnapshot(environment)
+
+	if environment.Variables, err = sealVariables(s.keyring, environment.Variables, &environment.DataKey, merged); err != nil {
+		return nil, err
+	}
+
+	if err := s.environmentRepo.Update(environment); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(environment.WorkspaceID, userID, models.AuditUpdate, models.AuditEntityEnvironment,
+		environment.ID, environment.Name, before, environmentSnapshot(environment))
+
+	environment.Variables = maskVariables(environment.Variables)
+	return environment, nil
+}
+
+// ExportEnvironment renders the variables of an environment as a dotenv or
+// JSON file. Secret values are left empty unless includeSecrets is set, which
+// requires edit access.
+func (s *EnvironmentService) ExportEnvironment(userID string, environmentID string, format string, includeSecrets bool) (*models.Environment, []byte, error) {
+	required := models.RoleViewer
+	if includeSecrets {
+		required = models.RoleEditor
+	}
+
+	environment, err := s.authorizeEnvironment(userID, environmentID, required)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variables := environment.Variables
+	if includeSecrets {
+		if variables, err = s.decryptVariables(environment); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		variables = make(models.VariableList, len(environment.Variables))
+		for i, variable := range environment.Variables {
+			if variable.Type == models.VariableSecret {
+				variable.Value = ""
+			}
+			variables[i] = variable
+		}
+	}
+
+	switch format {
+	case EnvironmentFormatDotenv, "":
+		entries := make([]dotenv.Entry, len(variables))
+		for i, variable := range variables {
+			entries[i] = dotenv.Entry{Key: variable.Key, Value: variable.Value, Enabled: variable.Enabled}
+		}
+		return environment, dotenv.Write(entries), nil
+	case EnvironmentFormatJSON:
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"name":      environment.Name,
+			"variables": variables,
+		}, "", "  ")
+		if err != nil {
+			return nil, nil, err
+		}
+		return environment, data, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidEnvironmentFile, format)
+	}
+}
+
+// decryptVariables returns a copy of the variables of an environment with
+// secret values decrypted
+func (s *EnvironmentService) decryptVariables(environment *models.Environment) (models.VariableList, error) {
+	variables := make(models.VariableList, len(environment.Variables))
+	copy(variables, environment.Variables)
+
+	var dataKey []byte
+	for i := range variables {
+		if variables[i].Type != models.VariableSecret || variables[i].Value == "" {
+			continue
+		}
+
+		if dataKey == nil {
+			var err error
+			if dataKey, err = s.keyring.UnwrapDataKey(environment.DataKey); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+			}
+		}
+
+		plaintext, err := secrets.Decrypt(dataKey, variables[i].Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+		}
+		variables[i].Value = plaintext
+	}
+
+	return variables, nil
+}
+
+// importedVariable is a variable in a JSON export. Hand-written files often
+// leave out "enabled", so it is a pointer to tell a missing field from false.
+type importedVariable struct {
+	Key         string              `json:"key"`
+	Value       string              `json:"value"`
+	Type        models.VariableType `json:"type"`
+	Description string              `json:"description"`
+	Enabled     *bool               `json:"enabled"`
+}
+
+// importedVariables converts imported variables, enabling those that don't
+// say otherwise like the dotenv and key/value formats do
+func importedVariables(imported []importedVariable) []models.Variable {
+	variables := make([]models.Variable, len(imported))
+	for i, variable := range imported {
+		variables[i] = models.Variable{
+			Key:         variable.Key,
+			Value:       variable.Value,
+			Type:        variable.Type,
+			Description: variable.Description,
+			Enabled:     variable.Enabled == nil || *variable.Enabled,
+		}
+	}
+	return variables
+}
+
+// parseEnvironmentFile reads variables from a dotenv file, a JSON object of
+// key/value pairs, or a JSON export ({"variables": [...]} or a bare array)
+func parseEnvironmentFile(format string, data []byte) ([]models.Variable, error) {
+	switch format {
+	case EnvironmentFormatDotenv, "":
+		entries, err := dotenv.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEnvironmentFile, err)
+		}
+		variables := make([]models.Variable, len(entries))
+		for i, entry := range entries {
+			variables[i] = models.Variable{Key: entry.Key, Value: entry.Value, Enabled: entry.Enabled}
+		}
+		return variables, nil
+
+	case EnvironmentFormatJSON:
+		var export struct {
+			Variables []importedVariable `json:"variables"`
+		}
+		if err := json.Unmarshal(data, &export); err == nil && export.Variables != nil {
+			return importedVariables(export.Variables), nil
+		}
+
+		var list []importedVariable
+		if err := json.Unmarshal(data, &list); err == nil {
+			return importedVariables(list), nil
+		}
+
+		var pairs map[string]interface{}
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return nil, fmt.Errorf("%w: expected a JSON object or array of variables", ErrInvalidEnvironmentFile)
+		}
+		keys := make([]string, 0, len(pairs))
+		for key := range pairs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		variables := make([]models.Variable, 0, len(pairs))
+		for _, key := range keys {
+			variable := models.Variable{Key: key, Enabled: true}
+			switch value := pairs[key].(type) {
+			case string:
+				variable.Value = value
+			case float64:
+				variable.Value = strconv.FormatFloat(value, 'f', -1, 64)
+				variable.Type = models.VariableNumber
+			case bool:
+				variable.Value = strconv.FormatBool(value)
+				variable.Type = models.VariableBoolean
+			case nil:
+			default:
+				encoded, _ := json.Marshal(value)
+				variable.Value = string(encoded)
+				variable.Type = models.VariableJSON
+			}
+			variables = append(variables, variable)
+		}
+		return variables, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidEnvironmentFile, format)
+	}
 }
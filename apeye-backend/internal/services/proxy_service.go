@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/secrets"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProxyNotFound = errors.New("proxy not configured")
+	ErrInvalidProxy  = errors.New("invalid proxy")
+)
+
+type ProxyService struct {
+	proxyRepo        *repository.ProxyRepository
+	workspaceService *WorkspaceService
+	keyring          *secrets.Keyring
+}
+
+func NewProxyService(proxyRepo *repository.ProxyRepository, workspaceService *WorkspaceService, keyring *secrets.Keyring) *ProxyService {
+	return &ProxyService{
+		proxyRepo:        proxyRepo,
+		workspaceService: workspaceService,
+		keyring:          keyring,
+	}
+}
+
+type UpdateProxyInput struct {
+	URL      string   `json:"url" binding:"required"`
+	Username string   `json:"username"`
+	Password string   `json:"password"` // SecretMask keeps the stored password
+	Bypass   []string `json:"bypass"`
+}
+
+// GetWorkspaceProxy returns the proxy of a workspace with its password masked
+func (s *ProxyService) GetWorkspaceProxy(userID string, workspaceID string) (*models.ProxySetting, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	setting, err := s.proxyRepo.FindByWorkspaceID(workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		return nil, ErrProxyNotFound
+	}
+	return maskedProxy(setting), nil
+}
+
+// UpdateWorkspaceProxy sets the proxy of a workspace
+func (s *ProxyService) UpdateWorkspaceProxy(userID string, workspaceID string, input UpdateProxyInput) (*models.ProxySetting, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	setting, err := s.proxyRepo.FindByWorkspaceID(workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		setting = &models.ProxySetting{WorkspaceID: workspace.ID}
+	}
+
+	bypass := models.StringList{}
+	for _, host := range input.Bypass {
+		if host = strings.TrimSpace(host); host != "" {
+			bypass = append(bypass, host)
+		}
+	}
+
+	password := input.Password
+	if password == SecretMask && setting.Password != "" {
+		if password, err = s.decryptPassword(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	proxy := httpclient.ProxyConfig{
+		URL:      strings.TrimSpace(input.URL),
+		Username: input.Username,
+		Password: password,
+		Bypass:   bypass,
+	}
+	if err := httpclient.ValidateProxy(proxy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProxy, err)
+	}
+
+	setting.URL = proxy.URL
+	setting.Username = proxy.Username
+	setting.Bypass = bypass
+	setting.Password = ""
+	if password != "" {
+		dataKey, err := s.dataKey(setting)
+		if err != nil {
+			return nil, err
+		}
+		if setting.Password, err = secrets.Encrypt(dataKey, password); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.proxyRepo.Save(setting); err != nil {
+		return nil, err
+	}
+	return maskedProxy(setting), nil
+}
+
+// DeleteWorkspaceProxy removes the proxy of a workspace
+func (s *ProxyService) DeleteWorkspaceProxy(userID string, workspaceID string) error {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	return s.proxyRepo.DeleteByWorkspaceID(workspace.ID)
+}
+
+// RequestProxy returns the decrypted proxy of a workspace for httpclient, or
+// nil when none is set. Callers must have authorized access to the workspace.
+func (s *ProxyService) RequestProxy(workspaceID uuid.UUID) (*httpclient.ProxyConfig, error) {
+	setting, err := s.proxyRepo.FindByWorkspaceID(workspaceID)
+	if err != nil || setting == nil {
+		return nil, err
+	}
+
+	password := ""
+	if setting.Password != "" {
+		if password, err = s.decryptPassword(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	return &httpclient.ProxyConfig{
+		URL:      setting.URL,
+		Username: setting.Username,
+		Password: password,
+		Bypass:   setting.Bypass,
+	}, nil
+}
+
+// dataKey returns the setting's data key, creating one when missing
+func (s *ProxyService) dataKey(setting *models.ProxySetting) ([]byte, error) {
+	var key []byte
+	var err error
+	if setting.DataKey == "" {
+		key, setting.DataKey, err = s.keyring.GenerateDataKey()
+	} else {
+		key, err = s.keyring.UnwrapDataKey(setting.DataKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+	}
+	return key, nil
+}
+
+func (s *ProxyService) decryptPassword(setting *models.ProxySetting) (string, error) {
+	key, err := s.dataKey(setting)
+	if err != nil {
+		return "", err
+	}
+	password, err := secrets.Decrypt(key, setting.Password)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+	}
+	return password, nil
+}
+
+// maskedProxy returns a copy of the setting that is safe to send to clients
+func maskedProxy(setting *models.ProxySetting) *models.ProxySetting {
+	masked := *setting
+	if masked.Password != "" {
+		masked.Password = SecretMask
+	}
+	return &masked
+}
@@ -1,41 +1,363 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
 
+	"github.com/Akash-YS05/apeye-app/apeye-backend/config"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
 	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 )
 
+var (
+	ErrUnresolvedVariables      = errors.New("URL contains unresolved variables. Please select an environment with the required variables defined.")
+	ErrWebSocketSessionNotFound = errors.New("WebSocket session not found or expired")
+	ErrExecutionNotFound        = errors.New("execution not found or already finished")
+)
+
+// webSocketSessionTTL is how long a WebSocket session waits for the browser
+const webSocketSessionTTL = 30 * time.Second
+
+// webSocketSession is a prepared connection waiting for the browser to attach.
+// Browsers cannot send auth headers on a WebSocket, so the request is
+// authorized when the session is created and the socket only carries its ID.
+type webSocketSession struct {
+	userID      string
+	config      httpclient.RequestConfig
+	resolved    httpclient.RequestConfig
+	workspaceID uuid.UUID
+	jar         *httpclient.CookieJar
+	expiresAt   time.Time
+}
+
 type RequestService struct {
-	httpClient     *httpclient.Client
-	historyRepo    *repository.HistoryRepository
+	httpClient         *httpclient.Client
+	historyRepo        *repository.HistoryRepository
+	variableService    *VariableService
+	certificateService *CertificateService
+	proxyService       *ProxyService
+	cookieService      *CookieService
+	blobService        *BlobService
+	executions         *httpclient.Executions
+	bodies             *httpclient.ResponseBodies
+
+	webSocketMu       sync.Mutex
+	webSocketSessions map[string]*webSocketSession
 }
 
-func NewRequestService(historyRepo *repository.HistoryRepository) *RequestService {
+func NewRequestService(historyRepo *repository.HistoryRepository, variableService *VariableService, certificateService *CertificateService, proxyService *ProxyService, cookieService *CookieService, blobService *BlobService, responseConfig config.ResponseConfig, egressConfig config.EgressConfig) *RequestService {
 	return &RequestService{
-		httpClient:  httpclient.NewClient(),
-		historyRepo: historyRepo,
+		httpClient: httpclient.NewClientWithOptions(httpclient.ClientOptions{
+			MaxResponseSize:    responseConfig.MaxSize,
+			ResponseBufferSize: responseConfig.BufferSize,
+			TempDir:            responseConfig.TempDir,
+			Egress: &httpclient.EgressPolicy{
+				DenyPrivate: egressConfig.DenyPrivate,
+				AllowHosts:  egressConfig.AllowHosts,
+				DenyHosts:   egressConfig.DenyHosts,
+			},
+		}),
+		historyRepo:        historyRepo,
+		variableService:    variableService,
+		certificateService: certificateService,
+		proxyService:       proxyService,
+		cookieService:      cookieService,
+		blobService:        blobService,
+		executions:         httpclient.NewExecutions(),
+		bodies:             httpclient.NewResponseBodies(responseConfig.BodyTTL),
+		webSocketSessions:  make(map[string]*webSocketSession),
 	}
 }
 
-// ExecuteRequest executes an HTTP request and saves to history
-func (s *RequestService) ExecuteRequest(userID string, config httpclient.RequestConfig) (*httpclient.Response, error) {
+// ExecuteRequest executes an HTTP request and saves to history. The request
+// is aborted when ctx is done or the execution is cancelled.
+func (s *RequestService) ExecuteRequest(ctx context.Context, userID string, config httpclient.RequestConfig) (*httpclient.Response, error) {
+	config.Certificates = nil
+
+	ctx, executionID, done, err := s.executions.Start(ctx, userID, config)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	resolved, workspaceID, jar, err := s.prepare(userID, config)
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute the request
-	response, err := s.httpClient.Execute(config)
+	response, err := s.httpClient.Execute(ctx, resolved)
 	if err != nil {
 		return nil, err
 	}
+	response.ExecutionID = executionID
+	if err := s.bodies.Keep(userID, response); err != nil {
+		return nil, err
+	}
+
+	s.saveJar(workspaceID, jar)
 
 	// Save to history (async, don't block response)
-	go s.saveToHistory(userID, config, response)
+	go s.saveToHistory(userID, config, response.Status, response.Time, response)
+
+	return response, nil
+}
+
+// GetResponseBody returns the full body of one of the user's truncated
+// responses
+func (s *RequestService) GetResponseBody(userID, bodyID string) (*httpclient.StoredBody, error) {
+	return s.bodies.Get(userID, bodyID)
+}
+
+// ListExecutions returns the user's requests in flight
+func (s *RequestService) ListExecutions(userID string) []httpclient.Execution {
+	return s.executions.List(userID)
+}
+
+// CancelExecution aborts one of the user's requests in flight
+func (s *RequestService) CancelExecution(userID, executionID string) error {
+	if !s.executions.Cancel(userID, executionID) {
+		return ErrExecutionNotFound
+	}
+	return nil
+}
+
+// ExecuteStream executes an HTTP request and passes the response to emit as
+// it streams in. Cancelling ctx stops the stream; the summary is saved to
+// history either way.
+func (s *RequestService) ExecuteStream(ctx context.Context, userID string, config httpclient.RequestConfig, emit func(httpclient.StreamMessage) error) (*httpclient.StreamResult, error) {
+	config.Certificates = nil
+
+	ctx, executionID, done, err := s.executions.Start(ctx, userID, config)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	resolved, workspaceID, jar, err := s.prepare(userID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.httpClient.ExecuteStream(ctx, resolved, emit)
+	if err != nil {
+		return nil, err
+	}
+	result.ExecutionID = executionID
+
+	s.saveJar(workspaceID, jar)
+	go s.saveToHistory(userID, config, result.Status, result.Time, result)
+
+	return result, nil
+}
+
+// IntrospectGraphQL fetches the schema of the GraphQL endpoint in config,
+// resolved the same way as an executed request. Schemas are cached unless
+// refresh is set.
+func (s *RequestService) IntrospectGraphQL(ctx context.Context, userID string, config httpclient.RequestConfig, refresh bool) (*httpclient.GraphQLSchema, error) {
+	config.Certificates = nil
+
+	resolved, workspaceID, jar, err := s.prepare(userID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := s.httpClient.Introspect(ctx, resolved, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	s.saveJar(workspaceID, jar)
+	// Report the URL as entered, not with secrets substituted
+	schema.URL = config.URL
+
+	return schema, nil
+}
+
+// ListGRPCServices discovers the services of a gRPC target, from the uploaded
+// proto files in config or else server reflection
+func (s *RequestService) ListGRPCServices(ctx context.Context, userID string, config httpclient.RequestConfig) ([]httpclient.GRPCService, error) {
+	config.Certificates = nil
+
+	resolved, _, _, err := s.prepare(userID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.httpClient.GRPCServices(ctx, resolved)
+}
+
+// ExecuteGRPC calls a gRPC method and saves the call to history
+func (s *RequestService) ExecuteGRPC(ctx context.Context, userID string, config httpclient.RequestConfig) (*httpclient.GRPCResponse, error) {
+	config.Certificates = nil
+	config.Method = string(models.MethodGRPC)
+
+	ctx, executionID, done, err := s.executions.Start(ctx, userID, config)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	resolved, _, _, err := s.prepare(userID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.httpClient.ExecuteGRPC(ctx, resolved)
+	if err != nil {
+		return nil, err
+	}
+	response.ExecutionID = executionID
+
+	go s.saveToHistory(userID, config, response.Status, response.Time, response)
 
 	return response, nil
 }
 
+// CreateWebSocketSession resolves a WebSocket request for the user and returns
+// the ID the browser connects with. The ID can be used once, within 30 seconds.
+func (s *RequestService) CreateWebSocketSession(userID string, config httpclient.RequestConfig) (string, error) {
+	config.Certificates = nil
+	config.Method = "GET"
+
+	resolved, workspaceID, jar, err := s.prepare(userID, config)
+	if err != nil {
+		return "", err
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(idBytes)
+
+	s.webSocketMu.Lock()
+	defer s.webSocketMu.Unlock()
+	now := time.Now()
+	for key, session := range s.webSocketSessions {
+		if now.After(session.expiresAt) {
+			delete(s.webSocketSessions, key)
+		}
+	}
+	s.webSocketSessions[id] = &webSocketSession{
+		userID:      userID,
+		config:      config,
+		resolved:    resolved,
+		workspaceID: workspaceID,
+		jar:         jar,
+		expiresAt:   now.Add(webSocketSessionTTL),
+	}
+
+	return id, nil
+}
+
+// HasWebSocketSession reports whether a session can still be attached to
+func (s *RequestService) HasWebSocketSession(id string) bool {
+	s.webSocketMu.Lock()
+	defer s.webSocketMu.Unlock()
+	session, ok := s.webSocketSessions[id]
+	return ok && time.Now().Before(session.expiresAt)
+}
+
+// RelayWebSocket connects a session to its target and relays frames to and
+// from the browser until either side closes. The transcript is saved to
+// history.
+func (s *RequestService) RelayWebSocket(ctx context.Context, id string, browser *websocket.Conn) (*httpclient.WebSocketTranscript, error) {
+	s.webSocketMu.Lock()
+	session, ok := s.webSocketSessions[id]
+	delete(s.webSocketSessions, id)
+	s.webSocketMu.Unlock()
+	if !ok || time.Now().After(session.expiresAt) {
+		browser.Close()
+		return nil, ErrWebSocketSessionNotFound
+	}
+
+	transcript, err := s.httpClient.RelayWebSocket(ctx, session.resolved, browser)
+	if err != nil {
+		return nil, err
+	}
+
+	s.saveJar(session.workspaceID, session.jar)
+	go s.saveToHistory(session.userID, session.config, transcript.Status, transcript.Duration, transcript)
+
+	return transcript, nil
+}
+
+// prepare returns the copy of a request that is sent.
+// Variables from every scope, including decrypted secrets, are only substituted
+// into that copy; history keeps the placeholders. Only the user's uploaded
+// client certificates are used, never ones sent with the request. A request
+// without its own proxy uses the workspace proxy. With UseCookieJar the
+// workspace cookie jar is loaded; the jar is returned only to editors, who
+// save it with saveJar once the response is in.
+func (s *RequestService) prepare(userID string, config httpclient.RequestConfig) (httpclient.RequestConfig, uuid.UUID, *httpclient.CookieJar, error) {
+	resolver, workspaceID, err := s.variableService.BuildResolver(userID, config)
+	if err != nil {
+		return config, uuid.Nil, nil, err
+	}
+	resolved := resolver.Apply(config)
+
+	if httpclient.HasUnresolvedVariables(resolved.URL) {
+		return config, uuid.Nil, nil, ErrUnresolvedVariables
+	}
+
+	resolved.Certificates, err = s.certificateService.RequestCertificates(userID)
+	if err != nil {
+		return config, uuid.Nil, nil, err
+	}
+
+	if err := s.blobService.AttachBody(userID, &resolved.Body); err != nil {
+		return config, uuid.Nil, nil, err
+	}
+
+	if resolved.Proxy == nil || resolved.Proxy.URL == "" {
+		resolved.Proxy, err = s.proxyService.RequestProxy(workspaceID)
+		if err != nil {
+			return config, uuid.Nil, nil, err
+		}
+	}
+
+	var jar *httpclient.CookieJar
+	if config.UseCookieJar {
+		loaded, err := s.cookieService.Jar(workspaceID)
+		if err != nil {
+			return config, uuid.Nil, nil, err
+		}
+		resolved.Jar = loaded
+
+		writable, err := s.cookieService.CanSaveJar(userID, workspaceID)
+		if err != nil {
+			return config, uuid.Nil, nil, err
+		}
+		if writable {
+			jar = loaded
+		}
+	}
+
+	return resolved, workspaceID, jar, nil
+}
+
+// saveJar persists cookie changes from a response
+func (s *RequestService) saveJar(workspaceID uuid.UUID, jar *httpclient.CookieJar) {
+	if jar == nil {
+		return
+	}
+	if err := s.cookieService.SaveJar(workspaceID, jar); err != nil {
+		log.Printf("Failed to save cookies for workspace %s: %v", workspaceID, err)
+	}
+}
+
 // saveToHistory saves request/response to history
-func (s *RequestService) saveToHistory(userID string, config httpclient.RequestConfig, response *httpclient.Response) {
+func (s *RequestService) saveToHistory(userID string, config httpclient.RequestConfig, status int, responseTime int64, response interface{}) {
 	// Convert config to JSONB
 	requestData, _ := json.Marshal(config)
 	responseData, _ := json.Marshal(response)
@@ -46,8 +368,8 @@ func (s *RequestService) saveToHistory(userID string, config httpclient.RequestC
 		URL:          config.URL,
 		RequestData:  models.JSONB{},
 		ResponseData: models.JSONB{},
-		StatusCode:   response.Status,
-		ResponseTime: int(response.Time),
+		StatusCode:   status,
+		ResponseTime: int(responseTime),
 	}
 
 	// Parse JSONB
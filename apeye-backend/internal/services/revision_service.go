@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRevisionNotFound = errors.New("revision not found")
+)
+
+// RevisionService keeps the version history of collections and saved requests.
+// Callers are responsible for authorization.
+type RevisionService struct {
+	revisionRepo *repository.RevisionRepository
+}
+
+func NewRevisionService(revisionRepo *repository.RevisionRepository) *RevisionService {
+	return &RevisionService{
+		revisionRepo: revisionRepo,
+	}
+}
+
+// RevisionDiff describes the changes between two versions of an entity
+type RevisionDiff struct {
+	EntityType models.RevisionEntity `json:"entity_type"`
+	EntityID   uuid.UUID             `json:"entity_id"`
+	From       int                   `json:"from"`
+	To         int                   `json:"to"`
+	Changes    models.JSONB          `json:"changes"`
+}
+
+// Record stores a new revision with the entity's current state. When the entity
+// predates version history, before is stored first so the original definition
+// is not lost. Failures are logged rather than returned so history never blocks the change itself.
+func (s *RevisionService) Record(
+	entityType models.RevisionEntity,
+	entityID uuid.UUID,
+	workspaceID uuid.UUID,
+	authorID string,
+	before, after models.JSONB,
+) {
+	revision := &models.Revision{
+		WorkspaceID: workspaceID,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Snapshot:    after,
+		AuthorID:    authorID,
+	}
+	if err := s.revisionRepo.Create(revision, before); err != nil {
+		log.Printf("Failed to record revision of %s %s: %v", entityType, entityID, err)
+	}
+}
+
+// List returns all revisions of an entity, newest first
+func (s *RevisionService) List(entityType models.RevisionEntity, entityID uuid.UUID) ([]models.Revision, error) {
+	return s.revisionRepo.FindByEntity(entityType, entityID)
+}
+
+// Get returns a single revision of an entity
+func (s *RevisionService) Get(entityType models.RevisionEntity, entityID uuid.UUID, version int) (*models.Revision, error) {
+	revision, err := s.revisionRepo.FindByVersion(entityType, entityID, version)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, ErrRevisionNotFound
+	}
+	return revision, nil
+}
+
+// Diff compares two versions of an entity. A zero "to" means the latest
+// version and a zero "from" means the version right before "to".
+func (s *RevisionService) Diff(entityType models.RevisionEntity, entityID uuid.UUID, from, to int) (*RevisionDiff, error) {
+	if to <= 0 {
+		revisions, err := s.revisionRepo.FindByEntity(entityType, entityID)
+		if err != nil {
+			return nil, err
+		}
+		if len(revisions) == 0 {
+			return nil, ErrRevisionNotFound
+		}
+		to = revisions[0].Version
+	}
+	if from <= 0 {
+		from = to - 1
+	}
+
+	target, err := s.Get(entityType, entityID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Diffing the first version compares it against an empty definition
+	var base models.JSONB
+	if from > 0 {
+		source, err := s.Get(entityType, entityID, from)
+		if err != nil {
+			return nil, err
+		}
+		base = source.Snapshot
+	}
+
+	return &RevisionDiff{
+		EntityType: entityType,
+		EntityID:   entityID,
+		From:       from,
+		To:         to,
+		Changes:    diffSnapshots(base, target.Snapshot),
+	}, nil
+}
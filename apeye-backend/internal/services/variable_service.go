@@ -0,0 +1,453 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/httpclient"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/pkg/secrets"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSecretDecryption = errors.New("failed to decrypt secret variables")
+	ErrInvalidVariable  = errors.New("invalid variable")
+)
+
+// SecretMask replaces secret values in API responses. Sending it back
+// unchanged for a secret variable in an update keeps the stored secret.
+const SecretMask = "********"
+
+// VariableService manages global, workspace and collection variables and
+// resolves all variable scopes for request execution
+type VariableService struct {
+	variableSetRepo    *repository.VariableSetRepository
+	workspaceService   *WorkspaceService
+	collectionService  *CollectionService
+	environmentService *EnvironmentService
+	keyring            *secrets.Keyring
+}
+
+func NewVariableService(
+	variableSetRepo *repository.VariableSetRepository,
+	workspaceService *WorkspaceService,
+	collectionService *CollectionService,
+	environmentService *EnvironmentService,
+	keyring *secrets.Keyring,
+) *VariableService {
+	return &VariableService{
+		variableSetRepo:    variableSetRepo,
+		workspaceService:   workspaceService,
+		collectionService:  collectionService,
+		environmentService: environmentService,
+		keyring:            keyring,
+	}
+}
+
+type UpdateVariablesInput struct {
+	Variables []models.Variable `json:"variables" binding:"required"`
+}
+
+// GetGlobalVariables returns the user's own global variables
+func (s *VariableService) GetGlobalVariables(userID string) (*models.VariableSet, error) {
+	set, err := s.globalSet(userID)
+	if err != nil {
+		return nil, err
+	}
+	return maskedSet(set), nil
+}
+
+// UpdateGlobalVariables replaces the user's own global variables
+func (s *VariableService) UpdateGlobalVariables(userID string, input UpdateVariablesInput) (*models.VariableSet, error) {
+	set, err := s.globalSet(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveSet(set, input)
+}
+
+// GetWorkspaceVariables returns the globals of a workspace the user can view
+func (s *VariableService) GetWorkspaceVariables(userID string, workspaceID string) (*models.VariableSet, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := s.workspaceSet(workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	return maskedSet(set), nil
+}
+
+// UpdateWorkspaceVariables replaces the globals of a workspace
+func (s *VariableService) UpdateWorkspaceVariables(userID string, workspaceID string, input UpdateVariablesInput) (*models.VariableSet, error) {
+	workspace, err := s.workspaceService.ResolveWorkspace(userID, workspaceID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := s.workspaceSet(workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveSet(set, input)
+}
+
+// GetCollectionVariables returns the variables of a collection the user can view
+func (s *VariableService) GetCollectionVariables(userID string, collectionID string) (*models.VariableSet, error) {
+	collection, err := s.collectionService.authorizeCollection(userID, collectionID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := s.collectionSet(collection.ID)
+	if err != nil {
+		return nil, err
+	}
+	return maskedSet(set), nil
+}
+
+// UpdateCollectionVariables replaces the variables of a collection
+func (s *VariableService) UpdateCollectionVariables(userID string, collectionID string, input UpdateVariablesInput) (*models.VariableSet, error) {
+	collection, err := s.collectionService.authorizeCollection(userID, collectionID, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := s.collectionSet(collection.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveSet(set, input)
+}
+
+// BuildResolver loads every variable scope a request refers to, with secrets
+// decrypted. The workspace is taken from the environment, then the collection,
+// then config.WorkspaceID (the user's default workspace when empty). Values sent
+// by the client in config.Scopes are ignored, the server only trusts its own data.
+// The workspace is returned too, for settings that apply to the whole workspace.
+func (s *VariableService) BuildResolver(userID string, config httpclient.RequestConfig) (*httpclient.Resolver, uuid.UUID, error) {
+	layers := []httpclient.VariableLayer{config.RequestLayer()}
+	var workspaceID *uuid.UUID
+
+	if config.EnvironmentID != "" {
+		environment, layer, err := s.environmentService.environmentLayer(userID, config.EnvironmentID)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+		layers = append(layers, layer)
+		workspaceID = &environment.WorkspaceID
+	}
+
+	if config.CollectionID != "" {
+		collection, err := s.collectionService.authorizeCollection(userID, config.CollectionID, models.RoleViewer)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+		set, err := s.variableSetRepo.FindByCollectionID(collection.ID)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+		if err := s.appendLayer(&layers, httpclient.ScopeCollection, set); err != nil {
+			return nil, uuid.Nil, err
+		}
+		if workspaceID == nil {
+			workspaceID = &collection.WorkspaceID
+		}
+	}
+
+	if workspaceID == nil {
+		workspace, err := s.workspaceService.ResolveWorkspace(userID, config.WorkspaceID, models.RoleViewer)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+		workspaceID = &workspace.ID
+	}
+	set, err := s.variableSetRepo.FindByWorkspaceID(*workspaceID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	if err := s.appendLayer(&layers, httpclient.ScopeWorkspace, set); err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	set, err = s.variableSetRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	if err := s.appendLayer(&layers, httpclient.ScopeGlobal, set); err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	return httpclient.NewResolver(layers...), *workspaceID, nil
+}
+
+// PreviewVariables lists the placeholders used by a request with the value
+// each resolves to and the scope it comes from. Secret values are masked.
+func (s *VariableService) PreviewVariables(userID string, config httpclient.RequestConfig) ([]httpclient.ResolvedVariable, error) {
+	resolver, _, err := s.BuildResolver(userID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := resolver.Preview(config)
+	for i := range preview {
+		if preview[i].Secret {
+			preview[i].Value = SecretMask
+		}
+	}
+	return preview, nil
+}
+
+func (s *VariableService) appendLayer(layers *[]httpclient.VariableLayer, scope httpclient.VariableScope, set *models.VariableSet) error {
+	if set == nil {
+		return nil
+	}
+	layer, err := variableLayer(scope, s.keyring, set.Variables, set.DataKey)
+	if err != nil {
+		return err
+	}
+	*layers = append(*layers, layer)
+	return nil
+}
+
+// globalSet returns the user's global variable set, unsaved when it does not exist yet
+func (s *VariableService) globalSet(userID string) (*models.VariableSet, error) {
+	set, err := s.variableSetRepo.FindByUserID(userID)
+	if err != nil || set != nil {
+		return set, err
+	}
+	return &models.VariableSet{Scope: models.VariableSetGlobal, UserID: &userID, Variables: models.VariableList{}}, nil
+}
+
+// workspaceSet returns the globals of a workspace, unsaved when they do not exist yet
+func (s *VariableService) workspaceSet(workspaceID uuid.UUID) (*models.VariableSet, error) {
+	set, err := s.variableSetRepo.FindByWorkspaceID(workspaceID)
+	if err != nil || set != nil {
+		return set, err
+	}
+	return &models.VariableSet{Scope: models.VariableSetWorkspace, WorkspaceID: &workspaceID, Variables: models.VariableList{}}, nil
+}
+
+// collectionSet returns the variables of a collection, unsaved when they do not exist yet
+func (s *VariableService) collectionSet(collectionID uuid.UUID) (*models.VariableSet, error) {
+	set, err := s.variableSetRepo.FindByCollectionID(collectionID)
+	if err != nil || set != nil {
+		return set, err
+	}
+	return &models.VariableSet{Scope: models.VariableSetCollection, CollectionID: &collectionID, Variables: models.VariableList{}}, nil
+}
+
+func (s *VariableService) saveSet(set *models.VariableSet, input UpdateVariablesInput) (*models.VariableSet, error) {
+	variables, err := sealVariables(s.keyring, set.Variables, &set.DataKey, input.Variables)
+	if err != nil {
+		return nil, err
+	}
+	set.Variables = variables
+
+	if err := s.variableSetRepo.Save(set); err != nil {
+		return nil, err
+	}
+	return maskedSet(set), nil
+}
+
+func maskedSet(set *models.VariableSet) *models.VariableSet {
+	set.Variables = maskVariables(set.Variables)
+	return set
+}
+
+// validateVariables checks keys are unique and values match their type.
+// An empty type defaults to string.
+func validateVariables(variables []models.Variable) (models.VariableList, error) {
+	result := make(models.VariableList, 0, len(variables))
+	seen := make(map[string]bool, len(variables))
+
+	for _, variable := range variables {
+		variable.Key = strings.TrimSpace(variable.Key)
+		if variable.Key == "" {
+			return nil, fmt.Errorf("%w: key is required", ErrInvalidVariable)
+		}
+		if seen[variable.Key] {
+			return nil, fmt.Errorf("%w: duplicate key %q", ErrInvalidVariable, variable.Key)
+		}
+		seen[variable.Key] = true
+
+		if variable.Type == "" {
+			variable.Type = models.VariableString
+		}
+		if !variable.Type.IsValid() {
+			return nil, fmt.Errorf("%w: unknown type %q for %q", ErrInvalidVariable, variable.Type, variable.Key)
+		}
+
+		if err := validateVariableValue(variable); err != nil {
+			return nil, err
+		}
+
+		result = append(result, variable)
+	}
+
+	return result, nil
+}
+
+// validateVariableValue checks a value can be read as its declared type
+func validateVariableValue(variable models.Variable) error {
+	value := strings.TrimSpace(variable.Value)
+	if value == "" {
+		return nil
+	}
+
+	switch variable.Type {
+	case models.VariableNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%w: %q must be a number", ErrInvalidVariable, variable.Key)
+		}
+	case models.VariableBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%w: %q must be true or false", ErrInvalidVariable, variable.Key)
+		}
+	case models.VariableJSON:
+		if !json.Valid([]byte(value)) {
+			return fmt.Errorf("%w: %q must be valid JSON", ErrInvalidVariable, variable.Key)
+		}
+	}
+	return nil
+}
+
+// sealVariables validates input and returns the variables to store, encrypting
+// secret values with the owner's data key (generated into dataKey on first use).
+// previous holds the currently stored variables: a masked value for a variable
+// that was already secret keeps its stored ciphertext.
+func sealVariables(keyring *secrets.Keyring, previous models.VariableList, dataKey *string, input []models.Variable) (models.VariableList, error) {
+	variables, err := validateVariables(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var key []byte
+	loadDataKey := func() ([]byte, error) {
+		if key != nil {
+			return key, nil
+		}
+		var err error
+		if *dataKey == "" {
+			key, *dataKey, err = keyring.GenerateDataKey()
+		} else {
+			key, err = keyring.UnwrapDataKey(*dataKey)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+		}
+		return key, nil
+	}
+
+	for i := range variables {
+		variable := &variables[i]
+
+		var stored string
+		wasSecret := false
+		if old, ok := previous.Find(variable.Key); ok && old.Type == models.VariableSecret {
+			stored = old.Value
+			wasSecret = true
+		}
+
+		// Resolve a mask sent back for a stored secret to the value it hides
+		if wasSecret && variable.Value == SecretMask {
+			if variable.Type == models.VariableSecret {
+				variable.Value = stored
+				continue
+			}
+			dk, err := loadDataKey()
+			if err != nil {
+				return nil, err
+			}
+			if variable.Value, err = secrets.Decrypt(dk, stored); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+			}
+			if err := validateVariableValue(*variable); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if variable.Type != models.VariableSecret {
+			continue
+		}
+
+		dk, err := loadDataKey()
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep the existing ciphertext when the secret did not change
+		if wasSecret {
+			if current, err := secrets.Decrypt(dk, stored); err == nil && current == variable.Value {
+				variable.Value = stored
+				continue
+			}
+		}
+
+		if variable.Value, err = secrets.Encrypt(dk, variable.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return variables, nil
+}
+
+// variableLayer returns the enabled variables as a resolver layer, decrypting
+// secret values. Only used for executing and previewing requests.
+func variableLayer(scope httpclient.VariableScope, keyring *secrets.Keyring, variables models.VariableList, dataKey string) (httpclient.VariableLayer, error) {
+	layer := httpclient.VariableLayer{
+		Scope:  scope,
+		Values: make(map[string]string, len(variables)),
+	}
+
+	var key []byte
+	for _, variable := range variables {
+		if !variable.Enabled {
+			continue
+		}
+
+		value := variable.Value
+		if variable.Type == models.VariableSecret && value != "" {
+			if key == nil {
+				var err error
+				if key, err = keyring.UnwrapDataKey(dataKey); err != nil {
+					return httpclient.VariableLayer{}, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+				}
+			}
+
+			plaintext, err := secrets.Decrypt(key, value)
+			if err != nil {
+				return httpclient.VariableLayer{}, fmt.Errorf("%w: %v", ErrSecretDecryption, err)
+			}
+			value = plaintext
+		}
+
+		if variable.Type == models.VariableSecret {
+			layer.Secrets = append(layer.Secrets, variable.Key)
+		}
+		layer.Values[variable.Key] = value
+	}
+
+	return layer, nil
+}
+
+// maskVariables returns a copy of variables with secret values replaced by
+// SecretMask, for API responses. The result must not be saved.
+func maskVariables(variables models.VariableList) models.VariableList {
+	masked := make(models.VariableList, len(variables))
+	for i, variable := range variables {
+		if variable.Type == models.VariableSecret {
+			variable.Value = SecretMask
+		}
+		masked[i] = variable
+	}
+	return masked
+}
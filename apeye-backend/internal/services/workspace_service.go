@@ -0,0 +1,468 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWorkspaceNotFound    = errors.New("workspace not found")
+	ErrInsufficientRole     = errors.New("insufficient workspace role")
+	ErrInvalidRole          = errors.New("invalid role, expected owner, editor or viewer")
+	ErrMemberNotFound       = errors.New("member not found")
+	ErrAlreadyMember        = errors.New("user is already a member of this workspace")
+	ErrLastOwner            = errors.New("workspace must keep at least one owner")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvitationNotPending = errors.New("invitation is no longer pending")
+	ErrEmailNotVerified     = errors.New("verify your email address to use invitations sent to it")
+	ErrPersonalWorkspace    = errors.New("the creator of a personal workspace cannot be removed from it")
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+type WorkspaceService struct {
+	workspaceRepo  *repository.WorkspaceRepository
+	memberRepo     *repository.WorkspaceMemberRepository
+	invitationRepo *repository.WorkspaceInvitationRepository
+	userRepo       *repository.UserRepository
+}
+
+func NewWorkspaceService(
+	workspaceRepo *repository.WorkspaceRepository,
+	memberRepo *repository.WorkspaceMemberRepository,
+	invitationRepo *repository.WorkspaceInvitationRepository,
+	userRepo *repository.UserRepository,
+) *WorkspaceService {
+	return &WorkspaceService{
+		workspaceRepo:  workspaceRepo,
+		memberRepo:     memberRepo,
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+	}
+}
+
+type CreateWorkspaceInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdateWorkspaceInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type InviteMemberInput struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type UpdateMemberInput struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// isDefaultWorkspaceID reports whether the client asked for the implicit default workspace
+func isDefaultWorkspaceID(workspaceID string) bool {
+	return workspaceID == "" ||
+		workspaceID == "default" ||
+		workspaceID == "default-workspace-id"
+}
+
+// Authorize checks that the user is a member of the workspace with at least the required role
+func (s *WorkspaceService) Authorize(userID string, workspaceID uuid.UUID, required models.WorkspaceRole) (*models.WorkspaceMember, error) {
+	member, err := s.memberRepo.FindByWorkspaceAndUser(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrUnauthorized
+	}
+	if !member.Role.Allows(required) {
+		return nil, ErrInsufficientRole
+	}
+	return member, nil
+}
+
+// ResolveWorkspace returns the requested workspace (or the user's default one)
+// after checking the user holds at least the required role in it
+func (s *WorkspaceService) ResolveWorkspace(userID string, workspaceID string, required models.WorkspaceRole) (*models.Workspace, error) {
+	if isDefaultWorkspaceID(workspaceID) {
+		workspace, err := s.workspaceRepo.FindDefaultByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		// The user created it, but may have been demoted since it was shared
+		member, err := s.Authorize(userID, workspace.ID, required)
+		if err != nil {
+			return nil, err
+		}
+		workspace.Role = member.Role
+		return workspace, nil
+	}
+
+	id, err := uuid.Parse(workspaceID)
+	if err != nil {
+		return nil, errors.New("invalid workspace ID format")
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	member, err := s.Authorize(userID, workspace.ID, required)
+	if err != nil {
+		return nil, err
+	}
+	workspace.Role = member.Role
+
+	return workspace, nil
+}
+
+// GetUserWorkspaces returns every workspace the user belongs to
+func (s *WorkspaceService) GetUserWorkspaces(userID string) ([]models.Workspace, error) {
+	// Make sure the user always has at least their own default workspace
+	if _, err := s.workspaceRepo.FindDefaultByUserID(userID); err != nil {
+		return nil, err
+	}
+
+	return s.workspaceRepo.FindByUserID(userID)
+}
+
+// CreateWorkspace creates a new workspace owned by the user
+func (s *WorkspaceService) CreateWorkspace(userID string, input CreateWorkspaceInput) (*models.Workspace, error) {
+	workspace := &models.Workspace{
+		UserID: userID,
+		Name:   input.Name,
+	}
+
+	if err := s.workspaceRepo.Create(workspace); err != nil {
+		return nil, err
+	}
+	workspace.Role = models.RoleOwner
+
+	return workspace, nil
+}
+
+// GetWorkspace returns a workspace the user can view
+func (s *WorkspaceService) GetWorkspace(userID string, workspaceID string) (*models.Workspace, error) {
+	return s.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
+}
+
+// UpdateWorkspace renames a workspace (owners only)
+func (s *WorkspaceService) UpdateWorkspace(userID string, workspaceID string, input UpdateWorkspaceInput) (*models.Workspace, error) {
+	workspace, err := s.ResolveWorkspace(userID, workspaceID, models.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace.Name = input.Name
+	if err := s.workspaceRepo.Update(workspace); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// DeleteWorkspace deletes a workspace and everything in it (owners only)
+func (s *WorkspaceService) DeleteWorkspace(userID string, workspaceID string) error {
+	workspace, err := s.ResolveWorkspace(userID, workspaceID, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	return s.workspaceRepo.Delete(workspace.ID)
+}
+
+// ListMembers returns all members of a workspace the user can view
+func (s *WorkspaceService) ListMembers(userID string, workspaceID string) ([]models.WorkspaceMember, error) {
+	workspace, err := s.ResolveWorkspace(userID, workspaceID, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.memberRepo.FindByWorkspaceID(workspace.ID)
+}
+
+// UpdateMemberRole changes a member's role (owners only)
+func (s *WorkspaceService) UpdateMemberRole(userID string, workspaceID string, memberUserID string, input UpdateMemberInput) (*models.WorkspaceMember, error) {
+	role := models.WorkspaceRole(input.Role)
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	workspace, err := s.ResolveWorkspace(userID, workspaceID, models.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.FindByWorkspaceAndUser(workspace.ID, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+
+	if member.Role == models.RoleOwner && role != models.RoleOwner {
+		if err := s.ensureAnotherOwner(workspace.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = role
+	if err := s.memberRepo.Update(member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// RemoveMember removes a member from a workspace. Owners can remove anyone,
+// other members can only remove themselves (leave the workspace).
+func (s *WorkspaceService) RemoveMember(userID string, workspaceID string, memberUserID string) error {
+	required := models.RoleOwner
+	if memberUserID == userID {
+		required = models.RoleViewer
+	}
+
+	workspace, err := s.ResolveWorkspace(userID, workspaceID, required)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.memberRepo.FindByWorkspaceAndUser(workspace.ID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrMemberNotFound
+	}
+
+	// It is the creator's default workspace, which they could not get back into
+	if workspace.Personal && workspace.UserID == memberUserID {
+		return ErrPersonalWorkspace
+	}
+
+	if member.Role == models.RoleOwner {
+		if err := s.ensureAnotherOwner(workspace.ID); err != nil {
+			return err
+		}
+	}
+
+	return s.memberRepo.Delete(workspace.ID, memberUserID)
+}
+
+// ensureAnotherOwner guards against demoting or removing the last owner
+func (s *WorkspaceService) ensureAnotherOwner(workspaceID uuid.UUID) error {
+	owners, err := s.memberRepo.CountByRole(workspaceID, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// InviteMember invites an email address to the workspace (owners only)
+func (s *WorkspaceService) InviteMember(userID string, workspaceID string, input InviteMemberInput) (*models.WorkspaceInvitation, error) {
+	role := models.WorkspaceRole(input.Role)
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	workspace, err := s.ResolveWorkspace(userID, workspaceID, models.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(input.Email)
+
+	// Reject invitations for people who already joined
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		member, err := s.memberRepo.FindByWorkspaceAndUser(workspace.ID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	// Re-inviting refreshes the open invitation instead of stacking duplicates
+	invitation, err := s.invitationRepo.FindPendingByWorkspaceAndEmail(workspace.ID, email)
+	if err != nil {
+		return nil, err
+	}
+	if invitation != nil {
+		invitation.Role = role
+		invitation.InvitedBy = userID
+		invitation.ExpiresAt = time.Now().UTC().Add(invitationTTL)
+		if err := s.invitationRepo.Update(invitation); err != nil {
+			return nil, err
+		}
+		return invitation, nil
+	}
+
+	invitation = &models.WorkspaceInvitation{
+		WorkspaceID: workspace.ID,
+		Email:       email,
+		Role:        role,
+		InvitedBy:   userID,
+		ExpiresAt:   time.Now().UTC().Add(invitationTTL),
+	}
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// ListWorkspaceInvitations returns pending invitations of a workspace (owners only)
+func (s *WorkspaceService) ListWorkspaceInvitations(userID string, workspaceID string) ([]models.WorkspaceInvitation, error) {
+	workspace, err := s.ResolveWorkspace(userID, workspaceID, models.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.invitationRepo.FindByWorkspaceID(workspace.ID)
+}
+
+// RevokeInvitation cancels a pending invitation (owners only)
+func (s *WorkspaceService) RevokeInvitation(userID string, workspaceID string, invitationID string) error {
+	workspace, err := s.ResolveWorkspace(userID, workspaceID, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := s.findInvitation(invitationID)
+	if err != nil {
+		return err
+	}
+	if invitation.WorkspaceID != workspace.ID {
+		return ErrInvitationNotFound
+	}
+	if invitation.Status != models.InvitationPending {
+		return ErrInvitationNotPending
+	}
+
+	invitation.Status = models.InvitationRevoked
+	return s.invitationRepo.Update(invitation)
+}
+
+// ListMyInvitations returns pending invitations addressed to the user's email
+func (s *WorkspaceService) ListMyInvitations(userID string) ([]models.WorkspaceInvitation, error) {
+	email, err := s.verifiedEmail(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.invitationRepo.FindPendingByEmail(email)
+}
+
+// AcceptInvitation joins the workspace with the invited role
+func (s *WorkspaceService) AcceptInvitation(userID string, invitationID string) (*models.WorkspaceMember, error) {
+	email, err := s.verifiedEmail(userID)
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := s.findInvitationFor(email, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.FindByWorkspaceAndUser(invitation.WorkspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		member = &models.WorkspaceMember{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      userID,
+			Role:        invitation.Role,
+		}
+		if err := s.memberRepo.Create(member); err != nil {
+			return nil, err
+		}
+	}
+
+	invitation.Status = models.InvitationAccepted
+	if err := s.invitationRepo.Update(invitation); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// DeclineInvitation rejects an invitation addressed to the user
+func (s *WorkspaceService) DeclineInvitation(userID string, invitationID string) error {
+	email, err := s.verifiedEmail(userID)
+	if err != nil {
+		return err
+	}
+	invitation, err := s.findInvitationFor(email, invitationID)
+	if err != nil {
+		return err
+	}
+
+	invitation.Status = models.InvitationDeclined
+	return s.invitationRepo.Update(invitation)
+}
+
+func (s *WorkspaceService) findInvitation(invitationID string) (*models.WorkspaceInvitation, error) {
+	id, err := uuid.Parse(invitationID)
+	if err != nil {
+		return nil, errors.New("invalid invitation ID")
+	}
+
+	invitation, err := s.invitationRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+// verifiedEmail returns the user's email address once it is verified.
+// Anyone can sign up with an address, so only a verified one proves the user
+// is the person an invitation was sent to.
+func (s *WorkspaceService) verifiedEmail(userID string) (string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", ErrUnauthorized
+	}
+	if !user.EmailVerified {
+		return "", ErrEmailNotVerified
+	}
+	return user.Email, nil
+}
+
+// findInvitationFor loads a pending, unexpired invitation addressed to email
+func (s *WorkspaceService) findInvitationFor(email string, invitationID string) (*models.WorkspaceInvitation, error) {
+	invitation, err := s.findInvitation(invitationID)
+	if err != nil {
+		return nil, err
+	}
+	// Don't reveal invitations that belong to someone else
+	if !strings.EqualFold(invitation.Email, email) {
+		return nil, ErrInvitationNotFound
+	}
+	if invitation.Status != models.InvitationPending || invitation.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvitationNotPending
+	}
+	return invitation, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/models"
+	"github.com/Akash-YS05/apeye-app/apeye-backend/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockDB returns a GORM database backed by sqlmock
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+func newTestWorkspaceService(db *gorm.DB) *WorkspaceService {
+	return NewWorkspaceService(
+		repository.NewWorkspaceRepository(db),
+		repository.NewWorkspaceMemberRepository(db),
+		repository.NewWorkspaceInvitationRepository(db),
+		repository.NewUserRepository(db),
+	)
+}
+
+// expectWorkspace expects ResolveWorkspace to load the workspace and the
+// caller's membership
+func expectWorkspace(mock sqlmock.Sqlmock, workspaceID uuid.UUID, creator string, personal bool, caller string, role models.WorkspaceRole) {
+	mock.ExpectQuery(`SELECT \* FROM "workspaces"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "userId", "name", "personal"}).
+			AddRow(workspaceID, creator, "Workspace", personal))
+	mock.ExpectQuery(`SELECT \* FROM "collections"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	expectMember(mock, workspaceID, caller, role)
+}
+
+func expectMember(mock sqlmock.Sqlmock, workspaceID uuid.UUID, userID string, role models.WorkspaceRole) {
+	mock.ExpectQuery(`SELECT \* FROM "workspace_members"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "userId", "role"}).
+			AddRow(uuid.New(), workspaceID, userID, role))
+}
+
+func TestRemoveMemberPersonalWorkspace(t *testing.T) {
+	tests := []struct {
+		name     string
+		personal bool
+		caller   string
+		member   string
+		wantErr  error
+	}{
+		{"creator leaves their personal workspace", true, "creator", "creator", ErrPersonalWorkspace},
+		{"co-owner removes the creator of a personal workspace", true, "co-owner", "creator", ErrPersonalWorkspace},
+		{"creator leaves a team workspace", false, "creator", "creator", nil},
+		{"co-owner leaves a personal workspace", true, "co-owner", "co-owner", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			service := newTestWorkspaceService(db)
+			workspaceID := uuid.New()
+
+			expectWorkspace(mock, workspaceID, "creator", tt.personal, tt.caller, models.RoleOwner)
+			expectMember(mock, workspaceID, tt.member, models.RoleOwner)
+			if tt.wantErr == nil {
+				mock.ExpectQuery(`SELECT count\(\*\) FROM "workspace_members"`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectExec(`DELETE FROM "workspace_members"`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			err := service.RemoveMember(tt.caller, workspaceID.String(), tt.member)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoveMember() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
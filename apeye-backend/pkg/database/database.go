@@ -60,21 +60,104 @@ func AutoMigrate() error {
 	// Only migrate tables that GORM manages (not Better-Auth tables)
 	err := DB.AutoMigrate(
 		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.WorkspaceInvitation{},
 		&models.Collection{},
 		&models.Request{},
 		&models.Environment{},
 		&models.History{},
 		&models.Subscription{},
+		&models.AuditEvent{},
+		&models.Revision{},
+		&models.VariableSet{},
+		&models.ClientCertificate{},
+		&models.ProxySetting{},
+		&models.Cookie{},
+		&models.Blob{},
 	)
 	
 	if err != nil {
 		return fmt.Errorf("failed to auto migrate: %w", err)
 	}
+
+	// Workspaces created before memberships existed only have an owner column
+	err = DB.Exec(`
+		INSERT INTO workspace_members (id, workspace_id, "userId", role, created_at, updated_at)
+		SELECT gen_random_uuid(), w.id, w."userId", 'owner', NOW(), NOW()
+		FROM workspaces w
+		WHERE NOT EXISTS (
+			SELECT 1 FROM workspace_members m
+			WHERE m.workspace_id = w.id AND m."userId" = w."userId"
+		)
+	`).Error
+	if err != nil {
+		return fmt.Errorf("failed to backfill workspace owners: %w", err)
+	}
+
+	// Each user's oldest own workspace becomes their personal (default) one
+	err = DB.Exec(`
+		UPDATE workspaces w SET personal = true
+		WHERE w.id = (
+			SELECT o.id FROM workspaces o
+			WHERE o."userId" = w."userId"
+			ORDER BY o.created_at ASC
+			LIMIT 1
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM workspaces p
+			WHERE p."userId" = w."userId" AND p.personal
+		)
+	`).Error
+	if err != nil {
+		return fmt.Errorf("failed to backfill personal workspaces: %w", err)
+	}
+	err = DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_workspaces_personal ON workspaces ("userId") WHERE personal`).Error
+	if err != nil {
+		return fmt.Errorf("failed to index personal workspaces: %w", err)
+	}
+
+	if err := migrateEnvironmentVariables(); err != nil {
+		return fmt.Errorf("failed to migrate environment variables: %w", err)
+	}
 	
 	log.Println("✅ Database migrations completed")
 	return nil
 }
 
+// migrateEnvironmentVariables converts environment variables stored as a
+// {"key": "value"} object into the ordered list of typed variables. Keys listed
+// in the old secret_keys column become secret variables.
+func migrateEnvironmentVariables() error {
+	secretCheck := "false"
+	hasSecretKeys := DB.Migrator().HasColumn("environments", "secret_keys")
+	if hasSecretKeys {
+		secretCheck = "COALESCE(e.secret_keys, '[]'::jsonb) @> to_jsonb(kv.key)"
+	}
+
+	err := DB.Exec(`
+		UPDATE environments e
+		SET variables = COALESCE((
+			SELECT jsonb_agg(jsonb_build_object(
+				'key', kv.key,
+				'value', kv.value,
+				'type', CASE WHEN ` + secretCheck + ` THEN 'secret' ELSE 'string' END,
+				'description', '',
+				'enabled', true
+			) ORDER BY kv.key)
+			FROM jsonb_each_text(e.variables) kv
+		), '[]'::jsonb)
+		WHERE jsonb_typeof(e.variables) = 'object'
+	`).Error
+	if err != nil {
+		return err
+	}
+
+	if hasSecretKeys {
+		return DB.Migrator().DropColumn("environments", "secret_keys")
+	}
+	return nil
+}
+
 // Close closes the database connection
 func Close() error {
 	sqlDB, err := DB.DB()
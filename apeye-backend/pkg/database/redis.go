@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/Akash-YS05/apeye-app/apeye-backend/config"
+	"github.com/redis/go-redis/v9"
+)
+
+var Redis *redis.Client
+
+// ConnectRedis creates the Redis client. Redis being down is not fatal: the
+// client reconnects on its own and callers fall back when commands fail.
+func ConnectRedis(cfg *config.RedisConfig) {
+	Redis = redis.NewClient(&redis.Options{
+		Addr:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Redis.Ping(ctx).Err(); err != nil {
+		log.Printf("⚠️  Redis unavailable, rate limits are not enforced until it is reachable: %v", err)
+		return
+	}
+	log.Println("✅ Redis connected successfully")
+}
+
+// CloseRedis closes the Redis client
+func CloseRedis() error {
+	if Redis == nil {
+		return nil
+	}
+	return Redis.Close()
+}
+
+// GetRedis returns the Redis client
+func GetRedis() *redis.Client {
+	return Redis
+}
@@ -0,0 +1,131 @@
+package dotenv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Entry is a single KEY=value line
+type Entry struct {
+	Key     string
+	Value   string
+	Enabled bool
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// Parse reads dotenv content. Blank lines and # comments are skipped, an
+// optional "export " prefix is allowed, and values may be single or double
+// quoted. Double quoted values support \n, \t, \" and \\ escapes.
+func Parse(data []byte) ([]Entry, error) {
+	var entries []Entry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNumber)
+		}
+
+		key = strings.TrimSpace(key)
+		if !keyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid key %q", lineNumber, key)
+		}
+
+		value, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		entries = append(entries, Entry{Key: key, Value: value, Enabled: true})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func parseValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch value[0] {
+	case '"':
+		end := closingQuote(value)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated double quote")
+		}
+		return unescape(value[1:end]), nil
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return value[1 : end+1], nil
+	}
+
+	// Unquoted values end at an inline comment
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// closingQuote returns the index of the unescaped double quote closing value
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unescape(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(value)
+}
+
+// Write formats entries as dotenv content. Values that need it are double
+// quoted, and disabled entries are written commented out.
+func Write(entries []Entry) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		if !entry.Enabled {
+			buf.WriteString("# ")
+		}
+		buf.WriteString(entry.Key)
+		buf.WriteByte('=')
+		buf.WriteString(quote(entry.Value))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func quote(value string) string {
+	if value == "" || !strings.ContainsAny(value, " \t\r\n\"'#\\=") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,280 @@
+package httpclient
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxResponseSize    = 100 << 20
+	DefaultResponseBufferSize = 5 << 20
+
+	// responsePreviewSize is how much of a truncated body is returned inline
+	responsePreviewSize = 64 << 10
+
+	// maxBodiesPerOwner bounds the kept bodies of one owner; the oldest go first
+	maxBodiesPerOwner = 10
+)
+
+var (
+	ErrResponseTooLarge     = errors.New("response exceeds the maximum response size")
+	ErrResponseBodyNotFound = errors.New("response body not found or expired")
+)
+
+// responseBody is a read response body. Bodies larger than the buffer size
+// are written to a temp file and only their start is kept in memory.
+type responseBody struct {
+	data     []byte // whole body, or the preview when file is set
+	file     string
+	size     int64 // bytes of data or file, after decoding
+	wireSize int64 // bytes received, before decoding
+	decoded  bool  // the content encoding was undone
+}
+
+// maxResponseSize returns the configured maximum response size
+func (c *Client) maxResponseSize() int64 {
+	if c.options.MaxResponseSize <= 0 {
+		return DefaultMaxResponseSize
+	}
+	return c.options.MaxResponseSize
+}
+
+// readBody reads resp's body, undoing its content encoding unless raw is set.
+// It spills the body to a temp file beyond the buffer size and fails once the
+// body exceeds the maximum response size, which bounds the decoded size so
+// compression bombs fail too.
+func (c *Client) readBody(resp *http.Response, raw bool) (*responseBody, error) {
+	maxSize := c.maxResponseSize()
+	bufferSize := c.options.ResponseBufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultResponseBufferSize
+	}
+	if bufferSize > maxSize {
+		bufferSize = maxSize
+	}
+
+	tooLarge := fmt.Errorf("%w of %d bytes", ErrResponseTooLarge, maxSize)
+	if resp.ContentLength > maxSize {
+		return nil, tooLarge
+	}
+
+	wire := &countingReader{r: resp.Body}
+	buffered := bufio.NewReader(wire)
+	var reader io.Reader = buffered
+	result := &responseBody{}
+
+	// Empty bodies, as for HEAD or 204, have nothing to decode
+	encodings := contentEncodings(resp.Header)
+	if _, err := buffered.Peek(1); err == nil && !raw && supportedEncoding(encodings) {
+		decoded, closeDecoder, err := decodeReader(buffered, encodings)
+		if err != nil {
+			return nil, err
+		}
+		defer closeDecoder()
+		reader = decoded
+		result.decoded = true
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, bufferSize+1))
+	if err != nil {
+		return nil, readError(err)
+	}
+	if int64(len(data)) <= bufferSize {
+		result.data = data
+		result.size = int64(len(data))
+		result.wireSize = wire.n
+		return result, nil
+	}
+
+	file, err := os.CreateTemp(c.options.TempDir, "apeye-response-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to buffer response: %w", err)
+	}
+	written, err := file.Write(data)
+	if err == nil {
+		var copied int64
+		copied, err = io.Copy(file, io.LimitReader(reader, maxSize-int64(written)+1))
+		written += int(copied)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && int64(written) > maxSize {
+		err = tooLarge
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		if errors.Is(err, ErrResponseTooLarge) {
+			return nil, err
+		}
+		return nil, readError(err)
+	}
+
+	preview := data
+	if len(preview) > responsePreviewSize {
+		preview = preview[:responsePreviewSize]
+	}
+	result.data = preview
+	result.file = file.Name()
+	result.size = int64(written)
+	result.wireSize = wire.n
+	return result, nil
+}
+
+// readError wraps an error reading the body; decoding errors say so already
+func readError(err error) error {
+	var decodeErr *decodeError
+	if errors.As(err, &decodeErr) {
+		return err
+	}
+	return fmt.Errorf("failed to read response: %w", err)
+}
+
+// previewText is the inline text of a truncated body. A multi-byte character
+// cut at the end is dropped.
+func previewText(preview []byte) string {
+	return strings.ToValidUTF8(string(preview), "")
+}
+
+// StoredBody is a kept response body, see ResponseBodies
+type StoredBody struct {
+	ID          string
+	ContentType string
+	Size        int64
+	path        string
+	owner       string
+	keptAt      time.Time
+}
+
+// Open opens the body for reading
+func (body *StoredBody) Open() (*os.File, error) {
+	file, err := os.Open(body.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrResponseBodyNotFound
+	}
+	return file, err
+}
+
+// Filename is the name to save the body as, with an extension matching its
+// content type when one is known
+func (body *StoredBody) Filename() string {
+	name := "response-" + body.ID[:8]
+	if mediaType, _, err := mime.ParseMediaType(body.ContentType); err == nil {
+		if extensions, _ := mime.ExtensionsByType(mediaType); len(extensions) > 0 {
+			name += extensions[0]
+		}
+	}
+	return name
+}
+
+// ResponseBodies keeps the full bodies of truncated responses on disk so they
+// can be downloaded after the response was returned. Bodies belong to an
+// owner, the user on the server, and are deleted after the TTL.
+type ResponseBodies struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	bodies map[string]*StoredBody
+}
+
+func NewResponseBodies(ttl time.Duration) *ResponseBodies {
+	return &ResponseBodies{ttl: ttl, bodies: make(map[string]*StoredBody)}
+}
+
+// Keep takes over the body file of a truncated response and sets its BodyID.
+// Responses that were not truncated are left alone.
+func (b *ResponseBodies) Keep(owner string, response *Response) error {
+	if response.BodyFile == "" {
+		return nil
+	}
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		os.Remove(response.BodyFile)
+		return err
+	}
+
+	info, err := os.Stat(response.BodyFile)
+	if err != nil {
+		os.Remove(response.BodyFile)
+		return err
+	}
+
+	body := &StoredBody{
+		ID:          hex.EncodeToString(idBytes),
+		ContentType: response.contentType(),
+		Size:        info.Size(),
+		path:        response.BodyFile,
+		owner:       owner,
+		keptAt:      time.Now(),
+	}
+
+	b.mu.Lock()
+	b.bodies[body.ID] = body
+	b.evict(owner)
+	b.mu.Unlock()
+
+	time.AfterFunc(b.ttl, func() { b.remove(body.ID) })
+	response.BodyID = body.ID
+	response.BodyFile = ""
+	return nil
+}
+
+// Get returns a kept body of owner
+func (b *ResponseBodies) Get(owner, id string) (*StoredBody, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	body, ok := b.bodies[id]
+	if !ok || body.owner != owner {
+		return nil, ErrResponseBodyNotFound
+	}
+	return body, nil
+}
+
+// evict removes the oldest bodies of owner beyond maxBodiesPerOwner; the
+// caller holds mu
+func (b *ResponseBodies) evict(owner string) {
+	var owned []*StoredBody
+	for _, body := range b.bodies {
+		if body.owner == owner {
+			owned = append(owned, body)
+		}
+	}
+	if len(owned) <= maxBodiesPerOwner {
+		return
+	}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].keptAt.Before(owned[j].keptAt) })
+	for _, body := range owned[:len(owned)-maxBodiesPerOwner] {
+		delete(b.bodies, body.ID)
+		os.Remove(body.path)
+	}
+}
+
+func (b *ResponseBodies) remove(id string) {
+	b.mu.Lock()
+	body, ok := b.bodies[id]
+	delete(b.bodies, id)
+	b.mu.Unlock()
+	if ok {
+		os.Remove(body.path)
+	}
+}
+
+// contentType returns the Content-Type header of the response
+func (response *Response) contentType() string {
+	for key, value := range response.Headers {
+		if strings.EqualFold(key, "Content-Type") {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrLocalFileUnsupported = errors.New("local file paths in request bodies are only supported by the local agent")
+	ErrBlobUnavailable      = errors.New("uploaded files can only be sent by the server; use a local path with the local agent")
+)
+
+// BodyFile is the content of a "binary" body taken from a file instead of
+// Content: a blob uploaded to the server, or a path read by the local agent.
+// Either way the file is streamed, not loaded into memory.
+type BodyFile struct {
+	BlobID      string `json:"blobId,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Name        string `json:"name,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+
+	// Open returns the blob's content and size. The server sets it once it
+	// has checked the user may read the blob.
+	Open func() (io.ReadCloser, int64, error) `json:"-"`
+}
+
+// fileBody is a request body streamed from a file of known size
+type fileBody struct {
+	io.ReadCloser
+	size int64
+}
+
+// openBodyFile opens the file of a binary body
+func (c *Client) openBodyFile(file *BodyFile) (io.Reader, string, error) {
+	var (
+		content io.ReadCloser
+		size    int64
+		name    = file.Name
+	)
+	switch {
+	case file.Path != "":
+		if !c.options.LocalFiles {
+			return nil, "", ErrLocalFileUnsupported
+		}
+		f, err := os.Open(file.Path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open body file: %w", err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, "", fmt.Errorf("failed to open body file: %w", err)
+		}
+		if info.IsDir() {
+			f.Close()
+			return nil, "", fmt.Errorf("body file %s is a directory", file.Path)
+		}
+		content, size = f, info.Size()
+		if name == "" {
+			name = filepath.Base(file.Path)
+		}
+	case file.BlobID != "":
+		if file.Open == nil {
+			return nil, "", ErrBlobUnavailable
+		}
+		var err error
+		if content, size, err = file.Open(); err != nil {
+			return nil, "", err
+		}
+	default:
+		return nil, "", errors.New("binary body file needs a blobId or a path")
+	}
+
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &fileBody{ReadCloser: content, size: size}, contentType, nil
+}
+
+// closeBody closes a request body that was built but not sent
+func closeBody(body io.Reader) {
+	if closer, ok := body.(io.Closer); ok {
+		closer.Close()
+	}
+}
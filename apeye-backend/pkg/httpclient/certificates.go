@@ -0,0 +1,208 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// maxCachedTransports bounds the transports kept for client certificates
+const maxCachedTransports = 64
+
+var ErrCertificateFilesNotAllowed = errors.New("certificate files on disk can only be used with the local agent")
+
+// ClientCertificate is a client certificate and/or CA bundle used for hosts
+// matching HostPattern, e.g. "api.internal", "*.corp.example.com" or
+// "localhost:8443". PEM data is sent inline; the *File fields are only read
+// by clients created with LocalFiles, i.e. the local agent.
+type ClientCertificate struct {
+	HostPattern     string `json:"hostPattern"`
+	Certificate     string `json:"certificate,omitempty"`
+	PrivateKey      string `json:"privateKey,omitempty"`
+	CA              string `json:"ca,omitempty"`
+	CertificateFile string `json:"certificateFile,omitempty"`
+	PrivateKeyFile  string `json:"privateKeyFile,omitempty"`
+	CAFile          string `json:"caFile,omitempty"`
+}
+
+// hostTLS is a loaded ClientCertificate
+type hostTLS struct {
+	pattern string
+	config  *tls.Config
+	key     string // identifies the certificate material, for transport reuse
+}
+
+// ValidateCertificate checks that a certificate with inline PEM data can be loaded
+func ValidateCertificate(cert ClientCertificate) error {
+	_, err := loadCertificate(cert, false)
+	return err
+}
+
+// matches reports whether the request URL is covered by the host pattern.
+// Patterns with a port are matched against host:port.
+func (h *hostTLS) matches(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(h.pattern, ":") {
+		port := u.Port()
+		if port == "" {
+			port = "443"
+			if u.Scheme == "http" || u.Scheme == "ws" || u.Scheme == "grpc" {
+				port = "80"
+			}
+		}
+		host = net.JoinHostPort(host, port)
+	}
+	ok, _ := path.Match(h.pattern, host)
+	return ok
+}
+
+// loadCertificate reads and parses a ClientCertificate
+func loadCertificate(cert ClientCertificate, localFiles bool) (*hostTLS, error) {
+	pattern := strings.ToLower(strings.TrimSpace(cert.HostPattern))
+	if pattern == "" {
+		return nil, errors.New("certificate host pattern is required")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid host pattern %q", cert.HostPattern)
+	}
+
+	read := func(inline, file string) (string, error) {
+		if file == "" {
+			return inline, nil
+		}
+		if !localFiles {
+			return "", ErrCertificateFilesNotAllowed
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		return string(data), nil
+	}
+	certPEM, err := read(cert.Certificate, cert.CertificateFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := read(cert.PrivateKey, cert.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	caPEM, err := read(cert.CA, cert.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if certPEM == "" && keyPEM == "" && caPEM == "" {
+		return nil, fmt.Errorf("certificate for %q has no certificate or CA bundle", cert.HostPattern)
+	}
+	if (certPEM == "") != (keyPEM == "") {
+		return nil, fmt.Errorf("certificate for %q needs both a certificate and a private key", cert.HostPattern)
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certPEM != "" {
+		pair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate for %q: %w", cert.HostPattern, err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+	if caPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+			return nil, fmt.Errorf("no certificates found in CA bundle for %q", cert.HostPattern)
+		}
+		config.RootCAs = pool
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{pattern, certPEM, keyPEM, caPEM}, "\x00")))
+	return &hostTLS{pattern: pattern, config: config, key: hex.EncodeToString(sum[:])}, nil
+}
+
+// clientFor returns the http.Client for a request, with the request's cookie
+// jar. With client certificates the transport picks a TLS configuration per
+// host, including redirects.
+func (c *Client) clientFor(config RequestConfig) (*http.Client, error) {
+	if len(config.Certificates) == 0 {
+		if config.Jar == nil {
+			return c.httpClient, nil
+		}
+		client := *c.httpClient
+		client.Jar = config.Jar
+		return &client, nil
+	}
+
+	certs := make([]*hostTLS, 0, len(config.Certificates))
+	for _, cert := range config.Certificates {
+		loaded, err := loadCertificate(cert, c.options.LocalFiles)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, loaded)
+	}
+
+	client := *c.httpClient
+	client.Transport = &certificateTransport{client: c, certs: certs}
+	client.Jar = config.Jar
+	return &client, nil
+}
+
+// certificateTransport routes requests to a transport configured with the
+// first certificate matching the host
+type certificateTransport struct {
+	client *Client
+	certs  []*hostTLS
+}
+
+func (t *certificateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for _, cert := range t.certs {
+		if cert.matches(req.URL) {
+			return t.client.transports.get(t.client.transport, cert).RoundTrip(req)
+		}
+	}
+	return t.client.transport.RoundTrip(req)
+}
+
+// transportCache keeps one transport per certificate so connections made
+// with a certificate are only reused by requests using the same one
+type transportCache struct {
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+func newTransportCache() *transportCache {
+	return &transportCache{transports: make(map[string]*http.Transport)}
+}
+
+func (tc *transportCache) get(base *http.Transport, cert *hostTLS) *http.Transport {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if transport, ok := tc.transports[cert.key]; ok {
+		return transport
+	}
+	if len(tc.transports) >= maxCachedTransports {
+		for key, transport := range tc.transports {
+			transport.CloseIdleConnections()
+			delete(tc.transports, key)
+		}
+	}
+
+	transport := base.Clone()
+	transport.TLSClientConfig = cert.config
+	tc.transports[cert.key] = transport
+	return transport
+}
@@ -2,6 +2,7 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
@@ -16,23 +17,66 @@ import (
 // Client wraps http.Client with additional functionality
 type Client struct {
 	httpClient *http.Client
+	transport  *http.Transport
+	transports *transportCache
+	tokens     *tokenCache
+	schemas    *schemaCache
+	options    ClientOptions
+}
+
+// ClientOptions configures a Client
+type ClientOptions struct {
+	// LocalFiles lets requests reference files on disk, such as client
+	// certificates. Only the local agent enables it.
+	LocalFiles bool
+
+	// SystemProxy uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY for requests
+	// without a proxy of their own
+	SystemProxy bool
+
+	// MaxResponseSize fails requests whose response body is larger, and
+	// ResponseBufferSize is how much of a body is kept in memory; larger
+	// bodies are written to a temp file in TempDir and returned truncated.
+	// Zero uses DefaultMaxResponseSize and DefaultResponseBufferSize.
+	MaxResponseSize    int64
+	ResponseBufferSize int64
+	TempDir            string
+
+	// Egress restricts the addresses requests may connect to. Only the
+	// server sets it; nil allows everything.
+	Egress *EgressPolicy
 }
 
 // NewClient creates a new HTTP client
 func NewClient() *Client {
-	return &Client{
+	return NewClientWithOptions(ClientOptions{})
+}
+
+// NewClientWithOptions creates a new HTTP client with the given options
+func NewClientWithOptions(options ClientOptions) *Client {
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: false,
+		},
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	client := &Client{
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: false,
-				},
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
+		transport:  transport,
+		transports: newTransportCache(),
+		tokens:     newTokenCache(),
+		schemas:    newSchemaCache(),
+		options:    options,
 	}
+	transport.Proxy = client.proxyFor
+	transport.DialContext = client.dialContext
+	return client
 }
 
 // RequestConfig represents the configuration for an HTTP request
@@ -43,6 +87,38 @@ type RequestConfig struct {
 	Headers []KeyValue `json:"headers"`
 	Auth    Auth       `json:"auth"`
 	Body    Body       `json:"body"`
+
+	// Variable scopes, see Resolver for precedence. The server loads the
+	// referenced scopes itself (secrets included); the local agent resolves
+	// with the layers sent in Scopes.
+	WorkspaceID   string          `json:"workspaceId,omitempty"`
+	CollectionID  string          `json:"collectionId,omitempty"`
+	RequestID     string          `json:"requestId,omitempty"` // saved request, also scopes cached OAuth2 tokens
+	EnvironmentID string          `json:"environmentId,omitempty"`
+	Variables     []KeyValue      `json:"variables,omitempty"` // per-request overrides
+	Scopes        []VariableLayer `json:"scopes,omitempty"`
+
+	// Client certificates and CA bundles, matched by host
+	Certificates []ClientCertificate `json:"certificates,omitempty"`
+
+	// Proxy for this request; the server falls back to the workspace proxy
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+
+	// UseCookieJar sends and captures cookies with the workspace cookie jar,
+	// which the server loads into Jar
+	UseCookieJar bool           `json:"useCookieJar,omitempty"`
+	Jar          http.CookieJar `json:"-"`
+
+	// ExecutionID identifies an execution while it is in flight so it can be
+	// cancelled, see Executions. Generated when empty.
+	ExecutionID string `json:"executionId,omitempty"`
+
+	// RawResponse returns the body as received, without undoing its
+	// Content-Encoding; compressed bodies are then base64 encoded
+	RawResponse bool `json:"rawResponse,omitempty"`
+
+	// GRPC makes this a gRPC call to a grpc:// or grpcs:// URL, see ExecuteGRPC
+	GRPC *GRPCConfig `json:"grpc,omitempty"`
 }
 
 // KeyValue represents a key-value pair
@@ -61,13 +137,20 @@ type Auth struct {
 	Password *string `json:"password,omitempty"`
 	APIKey   *string `json:"apiKey,omitempty"`
 	APIValue *string `json:"apiValue,omitempty"`
+	AddTo    string  `json:"addTo,omitempty"` // where the API key goes: header (default), query or cookie
+
+	OAuth2 *OAuth2Config   `json:"oauth2,omitempty"`
+	AWS    *AWSSigV4Config `json:"aws,omitempty"`
+	HMAC   *HMACConfig     `json:"hmac,omitempty"`
 }
 
 // Body represents request body configuration
 type Body struct {
-	Type     string     `json:"type"`
-	Content  string     `json:"content"`
-	FormData []KeyValue `json:"formData,omitempty"`
+	Type     string       `json:"type"`
+	Content  string       `json:"content"`
+	FormData []KeyValue   `json:"formData,omitempty"`
+	GraphQL  *GraphQLBody `json:"graphql,omitempty"`
+	File     *BodyFile    `json:"file,omitempty"` // "binary" bodies sent from a file
 }
 
 // Response represents the HTTP response
@@ -77,19 +160,122 @@ type Response struct {
 	Headers    map[string]string `json:"headers"`
 	Data       interface{}       `json:"data"`
 	Time       int64             `json:"time"` // milliseconds
-	Size       int64             `json:"size"` // bytes
+
+	// Body sizes in bytes, as received and after undoing the Content-Encoding.
+	// DecompressedSize is zero for raw responses that were not decoded.
+	CompressedSize   int64  `json:"compressedSize"`
+	DecompressedSize int64  `json:"decompressedSize"`
+	ContentEncoding  string `json:"contentEncoding,omitempty"`
+
+	// Charset the body was converted to UTF-8 from, when it is text
+	Charset string `json:"charset,omitempty"`
+
+	// DataEncoding is "base64" when Data holds raw compressed bytes
+	DataEncoding string `json:"dataEncoding,omitempty"`
+
+	// Truncated is set when the body was too large to return inline. Data is
+	// then a text preview of its start and the full body is in BodyFile until
+	// ResponseBodies keeps it for download as BodyID.
+	Truncated bool   `json:"truncated,omitempty"`
+	BodyID    string `json:"bodyId,omitempty"`
+	BodyFile  string `json:"-"`
+
+	// ID of the execution, which could be cancelled while in flight
+	ExecutionID string `json:"executionId,omitempty"`
+
+	// Entries of the errors array of a GraphQL response
+	GraphQLErrors []GraphQLError `json:"graphqlErrors,omitempty"`
 }
 
-// Execute performs the HTTP request
-func (c *Client) Execute(config RequestConfig) (*Response, error) {
+// Execute performs the HTTP request. Cancelling ctx aborts it.
+func (c *Client) Execute(ctx context.Context, config RequestConfig) (*Response, error) {
 	startTime := time.Now()
 
-	// Build URL with query parameters
-	requestURL, err := c.buildURL(config.URL, config.Params)
+	resp, err := c.send(ctx, withAcceptEncoding(config), false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := c.readBody(resp, config.RawResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate duration
+	duration := time.Since(startTime).Milliseconds()
+
+	// Parse response
+	response := &Response{
+		Status:          resp.StatusCode,
+		StatusText:      resp.Status,
+		Headers:         responseHeaders(resp.Header),
+		Time:            duration,
+		CompressedSize:  body.wireSize,
+		ContentEncoding: strings.Join(contentEncodings(resp.Header), ", "),
+	}
+	if body.decoded || response.ContentEncoding == "" {
+		response.DecompressedSize = body.size
+	}
+	if body.file != "" {
+		response.Truncated = true
+		response.BodyFile = body.file
+	}
+
+	// Compressed bytes are not text
+	if response.ContentEncoding != "" && !body.decoded {
+		response.Data = base64.StdEncoding.EncodeToString(body.data)
+		response.DataEncoding = "base64"
+		return response, nil
+	}
+
+	// Parse response body; a truncated body is not parseable
+	contentType := resp.Header.Get("Content-Type")
+	data, name := decodeCharset(body.data, contentType)
+	response.Charset = name
+	if body.file != "" {
+		response.Data = previewText(data)
+		return response, nil
+	}
+	response.Data = c.parseResponseBody(data, contentType)
+	if config.Body.Type == "graphql" {
+		response.GraphQLErrors = graphQLErrors(response.Data)
+	}
+
+	return response, nil
+}
+
+// send builds and sends the request and returns the response with its body
+// unread. Streaming requests are not bound by the client timeout, only by ctx.
+func (c *Client) send(ctx context.Context, config RequestConfig, streaming bool) (*http.Response, error) {
+	// Build URL with query parameters, including an API key sent in the query string
+	params := config.Params
+	if param, ok := config.Auth.apiKeyQueryParam(); ok {
+		params = append(append([]KeyValue{}, params...), param)
+	}
+	// GraphQL over GET sends the operation in the query string
+	if config.Body.Type == "graphql" && config.Method == "GET" {
+		operation, err := graphQLParams(config.Body.GraphQL)
+		if err != nil {
+			return nil, err
+		}
+		params = append(append([]KeyValue{}, params...), operation...)
+	}
+	requestURL, err := c.buildURL(config.URL, params)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
+	// Fetch the OAuth2 token first so token errors are reported before anything is sent
+	var token *OAuth2Token
+	if config.Auth.Type == "oauth2" {
+		token, err = c.oauth2Token(ctx, config.tokenOwner(), config.Auth.OAuth2)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Build request body
 	var bodyReader io.Reader
 	var contentType string
@@ -100,54 +286,118 @@ func (c *Client) Execute(config RequestConfig) (*Response, error) {
 		}
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequest(config.Method, requestURL, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// Signatures hash the exact payload and digest auth may send it twice, so
+	// buffer it; file bodies are read into memory in that case
+	var payload []byte
+	if config.Auth.needsPayload() && bodyReader != nil {
+		payload, err = io.ReadAll(bodyReader)
+		closeBody(bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build body: %w", err)
+		}
+		bodyReader = bytes.NewReader(payload)
 	}
 
-	// Set headers
-	c.setHeaders(req, config.Headers, contentType)
+	// Pick the client, with client certificates when configured
+	httpClient, err := c.clientFor(config)
+	if err != nil {
+		closeBody(bodyReader)
+		return nil, err
+	}
+	if streaming {
+		unbounded := *httpClient
+		unbounded.Timeout = 0
+		httpClient = &unbounded
+	}
 
-	// Set authentication
-	c.setAuth(req, config.Auth)
+	// Create HTTP request
+	req, err := c.newRequest(ctx, config, requestURL, bodyReader, payload, contentType, token)
+	if err != nil {
+		closeBody(bodyReader)
+		return nil, err
+	}
 
 	// Execute request
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Digest auth answers the server's challenge and retries once
+	if config.Auth.Type == "digest" && resp.StatusCode == http.StatusUnauthorized {
+		if challenge, ok := parseDigestChallenge(resp.Header); ok {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			if bodyReader != nil {
+				bodyReader = bytes.NewReader(payload)
+			}
+			req, err = c.newRequest(ctx, config, requestURL, bodyReader, payload, contentType, token)
+			if err != nil {
+				return nil, err
+			}
+			if err := setDigestAuth(req, config.Auth, challenge, payload); err != nil {
+				return nil, fmt.Errorf("digest auth failed: %w", err)
+			}
+
+			resp, err = httpClient.Do(req)
+			if err != nil {
+				return nil, fmt.Errorf("request failed: %w", err)
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// newRequest creates the HTTP request with headers, auth and signatures applied
+func (c *Client) newRequest(ctx context.Context, config RequestConfig, requestURL string, body io.Reader, payload []byte, contentType string, token *OAuth2Token) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, config.Method, requestURL, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if file, ok := body.(*fileBody); ok {
+		req.ContentLength = file.size
+		if file.size == 0 {
+			file.Close()
+			req.Body = http.NoBody
+		}
 	}
 
-	// Calculate duration
-	duration := time.Since(startTime).Milliseconds()
+	req, err = withProxy(req, config.Proxy)
+	if err != nil {
+		return nil, err
+	}
 
-	// Parse response
-	response := &Response{
-		Status:     resp.StatusCode,
-		StatusText: resp.Status,
-		Headers:    make(map[string]string),
-		Time:       duration,
-		Size:       int64(len(body)),
+	// Set headers
+	c.setHeaders(req, config.Headers, contentType)
+
+	// Set authentication
+	c.setAuth(req, config.Auth, token)
+
+	// Sign last, once headers and body are final
+	switch config.Auth.Type {
+	case "aws-sigv4":
+		err = signSigV4(req, payload, config.Auth.AWS, time.Now())
+	case "hmac":
+		err = signHMAC(req, payload, config.Auth.HMAC, time.Now())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
 	}
 
-	// Extract headers
-	for key, values := range resp.Header {
+	return req, nil
+}
+
+// responseHeaders keeps the first value of each response header
+func responseHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for key, values := range header {
 		if len(values) > 0 {
-			response.Headers[key] = values[0]
+			headers[key] = values[0]
 		}
 	}
-
-	// Parse response body
-	response.Data = c.parseResponseBody(body, resp.Header.Get("Content-Type"))
-
-	return response, nil
+	return headers
 }
 
 // buildURL constructs the full URL with query parameters
@@ -188,6 +438,43 @@ func (c *Client) buildBody(body Body) (io.Reader, string, error) {
 	case "raw":
 		return bytes.NewBufferString(body.Content), "text/plain", nil
 
+	case "xml":
+		if err := validateXML(body.Content); err != nil {
+			return nil, "", err
+		}
+		return bytes.NewBufferString(body.Content), "application/xml", nil
+
+	case "html":
+		return bytes.NewBufferString(body.Content), "text/html; charset=utf-8", nil
+
+	case "yaml":
+		if err := validateYAML(body.Content); err != nil {
+			return nil, "", err
+		}
+		return bytes.NewBufferString(body.Content), "application/yaml", nil
+
+	case "msgpack":
+		// Written as JSON and sent as MessagePack
+		payload, err := encodeMsgpack(body.Content)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(payload), "application/msgpack", nil
+
+	case "binary":
+		if body.File != nil {
+			return c.openBodyFile(body.File)
+		}
+		// Base64 in Content, sent as the decoded bytes
+		payload, err := decodeBinary(body.Content)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(payload), "application/octet-stream", nil
+
+	case "graphql":
+		return buildGraphQLBody(body.GraphQL)
+
 	case "x-www-form-urlencoded":
 		formData := url.Values{}
 		if body.FormData != nil {
@@ -236,8 +523,33 @@ func (c *Client) setHeaders(req *http.Request, headers []KeyValue, defaultConten
 	}
 }
 
+// apiKeyQueryParam returns the API key as a query parameter when it is sent in the query string
+func (auth Auth) apiKeyQueryParam() (KeyValue, bool) {
+	if auth.Type != "api-key" || auth.AddTo != "query" || auth.APIKey == nil || auth.APIValue == nil || *auth.APIKey == "" {
+		return KeyValue{}, false
+	}
+	return KeyValue{Key: *auth.APIKey, Value: *auth.APIValue, Enabled: true}, true
+}
+
+// needsPayload reports whether the auth type needs the body bytes
+func (auth Auth) needsPayload() bool {
+	return auth.Type == "aws-sigv4" || auth.Type == "hmac" || auth.Type == "digest"
+}
+
+// tokenOwner returns what cached OAuth2 tokens belong to: the saved request,
+// else its collection
+func (config RequestConfig) tokenOwner() string {
+	if config.RequestID != "" {
+		return "request:" + config.RequestID
+	}
+	if config.CollectionID != "" {
+		return "collection:" + config.CollectionID
+	}
+	return ""
+}
+
 // setAuth sets authentication headers
-func (c *Client) setAuth(req *http.Request, auth Auth) {
+func (c *Client) setAuth(req *http.Request, auth Auth, token *OAuth2Token) {
 	switch auth.Type {
 	case "bearer":
 		if auth.Token != nil && *auth.Token != "" {
@@ -253,7 +565,19 @@ func (c *Client) setAuth(req *http.Request, auth Auth) {
 
 	case "api-key":
 		if auth.APIKey != nil && auth.APIValue != nil && *auth.APIKey != "" {
-			req.Header.Set(*auth.APIKey, *auth.APIValue)
+			switch auth.AddTo {
+			case "query":
+				// Already added to the URL by Execute
+			case "cookie":
+				req.AddCookie(&http.Cookie{Name: *auth.APIKey, Value: *auth.APIValue})
+			default:
+				req.Header.Set(*auth.APIKey, *auth.APIValue)
+			}
+		}
+
+	case "oauth2":
+		if token != nil {
+			req.Header.Set("Authorization", token.header())
 		}
 	}
 }
@@ -264,12 +588,25 @@ func (c *Client) parseResponseBody(body []byte, contentType string) interface{}
 		return nil
 	}
 
-	// Try to parse as JSON
-	if strings.Contains(contentType, "application/json") || strings.Contains(contentType, "text/json") {
+	// Parse structured formats into a tree
+	switch responseFormat(contentType) {
+	case "json":
 		var jsonData interface{}
 		if err := json.Unmarshal(body, &jsonData); err == nil {
 			return jsonData
 		}
+	case "xml":
+		if node, err := parseXML(body); err == nil {
+			return node
+		}
+	case "yaml":
+		if value, err := parseYAML(body); err == nil {
+			return value
+		}
+	case "msgpack":
+		if value, err := parseMsgpack(body); err == nil {
+			return value
+		}
 	}
 
 	// Return as string
@@ -0,0 +1,225 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Cookie is a cookie held by a CookieJar
+type Cookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain"`
+	Path     string     `json:"path"`
+	Expires  *time.Time `json:"expires,omitempty"` // nil for session cookies
+	Secure   bool       `json:"secure"`
+	HttpOnly bool       `json:"httpOnly"`
+	HostOnly bool       `json:"hostOnly"` // only sent to Domain itself, not its subdomains
+	SameSite string     `json:"sameSite,omitempty"`
+}
+
+// Key identifies a cookie within a jar
+func (c Cookie) Key() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+func (c Cookie) expired(now time.Time) bool {
+	return c.Expires != nil && !c.Expires.After(now)
+}
+
+// CookieJar is an http.CookieJar that starts from stored cookies and records
+// what responses change, so callers can persist the changes
+type CookieJar struct {
+	mu      sync.Mutex
+	cookies map[string]Cookie
+	set     map[string]Cookie
+	removed map[string]Cookie
+}
+
+// NewCookieJar creates a jar holding the given cookies
+func NewCookieJar(cookies []Cookie) *CookieJar {
+	jar := &CookieJar{
+		cookies: make(map[string]Cookie),
+		set:     make(map[string]Cookie),
+		removed: make(map[string]Cookie),
+	}
+	for _, cookie := range cookies {
+		jar.cookies[cookie.Key()] = cookie
+	}
+	return jar
+}
+
+// SetCookies stores cookies from a response to u
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	host := strings.ToLower(u.Hostname())
+	for _, c := range cookies {
+		cookie := Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			SameSite: sameSiteName(c.SameSite),
+		}
+
+		// A cookie for a public suffix such as "com" or "github.io" would be
+		// sent to every site under it, so it is only kept for that exact host
+		domain := strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+		switch {
+		case domain == "", domain == host && publicSuffix(domain):
+			cookie.Domain = host
+			cookie.HostOnly = true
+		case domainMatch(host, domain) && !publicSuffix(domain):
+			cookie.Domain = domain
+		default:
+			continue
+		}
+		if !strings.HasPrefix(cookie.Path, "/") {
+			cookie.Path = defaultCookiePath(u.Path)
+		}
+
+		switch {
+		case c.MaxAge < 0:
+			cookie.Expires = &now
+		case c.MaxAge > 0:
+			expires := now.Add(time.Duration(c.MaxAge) * time.Second)
+			cookie.Expires = &expires
+		case !c.Expires.IsZero():
+			expires := c.Expires
+			cookie.Expires = &expires
+		}
+
+		key := cookie.Key()
+		if cookie.expired(now) {
+			if old, ok := j.cookies[key]; ok {
+				delete(j.cookies, key)
+				delete(j.set, key)
+				j.removed[key] = old
+			}
+			continue
+		}
+		j.cookies[key] = cookie
+		j.set[key] = cookie
+		delete(j.removed, key)
+	}
+}
+
+// Cookies returns the cookies to send in a request to u, longest path first
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	host := strings.ToLower(u.Hostname())
+	requestPath := u.EscapedPath()
+	if requestPath == "" {
+		requestPath = "/"
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+
+	var matches []Cookie
+	for _, cookie := range j.cookies {
+		if cookie.expired(now) || (cookie.Secure && !secure) {
+			continue
+		}
+		if cookie.HostOnly && host != cookie.Domain {
+			continue
+		}
+		if !cookie.HostOnly && !domainMatch(host, cookie.Domain) {
+			continue
+		}
+		if !pathMatch(requestPath, cookie.Path) {
+			continue
+		}
+		matches = append(matches, cookie)
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		return len(matches[a].Path) > len(matches[b].Path)
+	})
+
+	result := make([]*http.Cookie, len(matches))
+	for i, cookie := range matches {
+		result[i] = &http.Cookie{Name: cookie.Name, Value: cookie.Value}
+	}
+	return result
+}
+
+// Changes returns the cookies set and removed since the jar was created
+func (j *CookieJar) Changes() (set []Cookie, removed []Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, cookie := range j.set {
+		set = append(set, cookie)
+	}
+	for _, cookie := range j.removed {
+		removed = append(removed, cookie)
+	}
+	return set, removed
+}
+
+// domainMatch reports whether host is domain or a subdomain of it (RFC 6265 5.1.3)
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return net.ParseIP(host) == nil && strings.HasSuffix(host, "."+domain)
+}
+
+// publicSuffix reports whether domain is a public suffix, under which
+// unrelated parties register names
+func publicSuffix(domain string) bool {
+	if net.ParseIP(domain) != nil {
+		return false
+	}
+	suffix, _ := publicsuffix.PublicSuffix(domain)
+	return suffix == domain
+}
+
+// pathMatch implements RFC 6265 5.1.4
+func pathMatch(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// defaultCookiePath implements RFC 6265 5.1.4
+func defaultCookiePath(requestPath string) string {
+	if !strings.HasPrefix(requestPath, "/") {
+		return "/"
+	}
+	i := strings.LastIndex(requestPath, "/")
+	if i == 0 {
+		return "/"
+	}
+	return requestPath[:i]
+}
+
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return ""
+	}
+}
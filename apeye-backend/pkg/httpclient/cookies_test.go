@@ -0,0 +1,52 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestSetCookiesDomain(t *testing.T) {
+	tests := []struct {
+		name       string
+		requestURL string
+		domain     string
+		wantDomain string // empty when the cookie is rejected
+		hostOnly   bool
+	}{
+		{"no domain", "https://api.example.com/", "", "api.example.com", true},
+		{"parent domain", "https://api.example.com/", "example.com", "example.com", false},
+		{"leading dot", "https://api.example.com/", ".example.com", "example.com", false},
+		{"unrelated domain", "https://api.example.com/", "other.com", "", false},
+		{"top-level domain", "https://api.example.com/", "com", "", false},
+		{"multi-label public suffix", "https://shop.example.co.uk/", "co.uk", "", false},
+		{"hosting public suffix", "https://user.github.io/", "github.io", "", false},
+		{"site under a public suffix", "https://shop.example.co.uk/", "example.co.uk", "example.co.uk", false},
+		{"public suffix host", "https://github.io/", "github.io", "github.io", true},
+		{"single-label host", "http://localhost:8080/", "localhost", "localhost", true},
+		{"IP address", "http://10.0.0.5/", "10.0.0.5", "10.0.0.5", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jar := NewCookieJar(nil)
+			u, _ := url.Parse(tt.requestURL)
+			jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "1", Domain: tt.domain}})
+
+			set, _ := jar.Changes()
+			if tt.wantDomain == "" {
+				if len(set) != 0 {
+					t.Fatalf("SetCookies() kept %+v, want it rejected", set[0])
+				}
+				return
+			}
+			if len(set) != 1 {
+				t.Fatalf("SetCookies() kept %d cookies, want 1", len(set))
+			}
+			if set[0].Domain != tt.wantDomain || set[0].HostOnly != tt.hostOnly {
+				t.Fatalf("SetCookies() domain = %q hostOnly = %v, want %q hostOnly = %v",
+					set[0].Domain, set[0].HostOnly, tt.wantDomain, tt.hostOnly)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package httpclient
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// digestChallenge is a parsed "WWW-Authenticate: Digest ..." header
+type digestChallenge map[string]string
+
+// parseDigestChallenge returns the first Digest challenge in the response headers
+func parseDigestChallenge(header http.Header) (digestChallenge, bool) {
+	for _, value := range header.Values("WWW-Authenticate") {
+		scheme, params, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+		challenge := digestChallenge(parseAuthParams(params))
+		if challenge["nonce"] == "" {
+			continue
+		}
+		return challenge, true
+	}
+	return nil, false
+}
+
+// parseAuthParams parses comma separated key=value pairs, values optionally quoted
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " ,\t")
+		if s == "" {
+			return params
+		}
+
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return params
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(rest, `"`) {
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			s = rest[min(i+1, len(rest)):]
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			value.WriteString(strings.TrimSpace(rest[:end]))
+			s = rest[end:]
+		}
+		params[key] = value.String()
+	}
+}
+
+// setDigestAuth answers a Digest challenge (RFC 7616) for the request. The
+// body is only needed for qop=auth-int.
+func setDigestAuth(req *http.Request, auth Auth, challenge digestChallenge, payload []byte) error {
+	if auth.Username == nil || auth.Password == nil {
+		return errors.New("digest auth requires a username and password")
+	}
+
+	algorithm := challenge["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	digest := func(parts ...string) string {
+		h := newHash()
+		h.Write([]byte(strings.Join(parts, ":")))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	qop := ""
+	for _, option := range strings.Split(challenge["qop"], ",") {
+		option = strings.TrimSpace(option)
+		if option == "auth" || (option == "auth-int" && qop == "") {
+			qop = option
+		}
+	}
+
+	cnonceBytes := make([]byte, 16)
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return err
+	}
+	cnonce := hex.EncodeToString(cnonceBytes)
+	nc := "00000001"
+	realm, nonce := challenge["realm"], challenge["nonce"]
+	uri := req.URL.RequestURI()
+
+	ha1 := digest(*auth.Username, realm, *auth.Password)
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = digest(ha1, nonce, cnonce)
+	}
+	ha2 := digest(req.Method, uri)
+	if qop == "auth-int" {
+		h := newHash()
+		h.Write(payload)
+		ha2 = digest(req.Method, uri, hex.EncodeToString(h.Sum(nil)))
+	}
+
+	var response string
+	if qop == "" {
+		response = digest(ha1, nonce, ha2)
+	} else {
+		response = digest(ha1, nonce, nc, cnonce, qop, ha2)
+	}
+
+	header := fmt.Sprintf(`Digest username=%q, realm=%q, nonce=%q, uri=%q, algorithm=%s, response=%q`,
+		*auth.Username, realm, nonce, uri, algorithm, response)
+	if qop != "" {
+		header += fmt.Sprintf(`, qop=%s, nc=%s, cnonce=%q`, qop, nc, cnonce)
+	}
+	if opaque, ok := challenge["opaque"]; ok {
+		header += fmt.Sprintf(`, opaque=%q`, opaque)
+	}
+	req.Header.Set("Authorization", header)
+	return nil
+}
@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrEgressDenied = errors.New("destination not allowed")
+
+// EgressPolicy restricts where a client may connect. The server executor uses
+// one so requests cannot reach its own network; the local agent runs without.
+type EgressPolicy struct {
+	// DenyPrivate blocks loopback, private, link-local (cloud metadata
+	// included), shared and other non-public addresses
+	DenyPrivate bool
+
+	// AllowHosts are reachable even when private, DenyHosts never are. Both
+	// take the same entries as a proxy bypass list: "api.corp", ".corp",
+	// "*.corp" or CIDR ranges. DenyHosts wins.
+	AllowHosts []string
+	DenyHosts  []string
+}
+
+// EgressError is a connection refused by the egress policy
+type EgressError struct {
+	Host   string
+	Reason string
+}
+
+func (e *EgressError) Error() string {
+	return fmt.Sprintf("requests to %s are blocked on the server: %s. Use the local agent to reach private and local addresses", e.Host, e.Reason)
+}
+
+func (e *EgressError) Unwrap() error {
+	return ErrEgressDenied
+}
+
+// nonPublicPrefixes are special-purpose ranges not covered by the netip
+// predicates in privateAddr
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved and broadcast
+	netip.MustParsePrefix("100::/64"),        // discard
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// embeddedIPv4Prefixes are IPv6 ranges that carry an IPv4 address in their
+// last four bytes, which translating gateways connect to
+var embeddedIPv4Prefixes = []netip.Prefix{
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+}
+
+// privateAddr reports whether addr is not a public unicast address
+func privateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.Is6() {
+		for _, prefix := range embeddedIPv4Prefixes {
+			if prefix.Contains(addr) {
+				bytes := addr.As16()
+				if privateAddr(netip.AddrFrom4([4]byte(bytes[12:]))) {
+					return true
+				}
+			}
+		}
+		// 6to4 carries the IPv4 address in bytes 2 to 5
+		if bytes := addr.As16(); bytes[0] == 0x20 && bytes[1] == 0x02 {
+			if privateAddr(netip.AddrFrom4([4]byte(bytes[2:6]))) {
+				return true
+			}
+		}
+	}
+
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHost applies the host lists to a host name or IP before it is resolved
+func (p *EgressPolicy) checkHost(host string) error {
+	if matchHosts(p.DenyHosts, host) {
+		return &EgressError{Host: host, Reason: "the host is on the deny list"}
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(host, ip)
+	}
+	return nil
+}
+
+// checkAddr applies the policy to an address host resolved to
+func (p *EgressPolicy) checkAddr(host string, addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+	if matchHosts(p.DenyHosts, addr.String()) {
+		return &EgressError{Host: host, Reason: fmt.Sprintf("%s is on the deny list", addr)}
+	}
+	if matchHosts(p.AllowHosts, host) || matchHosts(p.AllowHosts, addr.String()) {
+		return nil
+	}
+	if p.DenyPrivate && privateAddr(addr) {
+		if host == addr.String() {
+			return &EgressError{Host: host, Reason: "it is a private or local address"}
+		}
+		return &EgressError{Host: host, Reason: fmt.Sprintf("it resolves to the private or local address %s", addr)}
+	}
+	return nil
+}
+
+// checkProxied applies the policy to a host reached through a proxy, where
+// dialContext only sees the proxy's address. The host is resolved here; the
+// proxy may get other addresses, but names of private addresses are caught.
+func (p *EgressPolicy) checkProxied(ctx context.Context, host string) error {
+	if err := p.checkHost(host); err != nil {
+		return err
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return &EgressError{Host: host, Reason: "it could not be resolved to check its address"}
+	}
+	for _, addr := range addrs {
+		if err := p.checkAddr(host, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dialContext dials through the egress policy. The host is checked before it
+// is resolved and every address is checked again right before connecting, so
+// a DNS answer that changes between lookups (DNS rebinding) cannot slip
+// through. Without a policy it dials directly.
+func (c *Client) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	policy := c.options.Egress
+	if policy == nil {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.checkHost(host); err != nil {
+		return nil, err
+	}
+
+	dialer.Control = func(_, resolved string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(resolved)
+		if err != nil {
+			return &EgressError{Host: host, Reason: "its address could not be checked"}
+		}
+		return policy.checkAddr(host, addrPort.Addr())
+	}
+	return dialer.DialContext(ctx, network, address)
+}
@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestPrivateAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		private bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true}, // cloud metadata
+		{"100.64.0.1", true},      // carrier-grade NAT
+		{"100.127.255.254", true},
+		{"0.0.0.0", true},
+		{"224.0.0.1", true},
+		{"255.255.255.255", true},
+		{"::1", true},
+		{"::", true},
+		{"fe80::1", true},
+		{"fc00::1", true},
+		{"::ffff:127.0.0.1", true},       // IPv4-mapped loopback
+		{"::ffff:169.254.169.254", true}, // IPv4-mapped metadata
+		{"64:ff9b::a9fe:a9fe", true},     // NAT64 of 169.254.169.254
+		{"64:ff9b::a00:1", true},         // NAT64 of 10.0.0.1
+		{"2002:a9fe:a9fe::", true},       // 6to4 of 169.254.169.254
+		{"2002:7f00:1::", true},          // 6to4 of 127.0.0.1
+		{"8.8.8.8", false},
+		{"100.128.0.1", false},
+		{"::ffff:8.8.8.8", false},
+		{"64:ff9b::808:808", false}, // NAT64 of 8.8.8.8
+		{"2002:808:808::", false},   // 6to4 of 8.8.8.8
+		{"2606:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		if got := privateAddr(netip.MustParseAddr(tt.addr)); got != tt.private {
+			t.Errorf("privateAddr(%s) = %v, want %v", tt.addr, got, tt.private)
+		}
+	}
+}
+
+func TestCheckAddr(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  EgressPolicy
+		host    string
+		addr    string
+		allowed bool
+	}{
+		{"public address", EgressPolicy{DenyPrivate: true}, "example.com", "93.184.216.34", true},
+		{"private address", EgressPolicy{DenyPrivate: true}, "internal.corp", "10.0.0.5", false},
+		{"metadata address", EgressPolicy{DenyPrivate: true}, "metadata.google.internal", "169.254.169.254", false},
+		{"mapped private address", EgressPolicy{DenyPrivate: true}, "rebind.example", "::ffff:10.0.0.5", false},
+		{"private allowed without DenyPrivate", EgressPolicy{}, "internal.corp", "10.0.0.5", true},
+		{"allowed host", EgressPolicy{DenyPrivate: true, AllowHosts: []string{"internal.corp"}}, "internal.corp", "10.0.0.5", true},
+		{"allowed domain", EgressPolicy{DenyPrivate: true, AllowHosts: []string{".corp"}}, "api.internal.corp", "10.0.0.5", true},
+		{"allowed range", EgressPolicy{DenyPrivate: true, AllowHosts: []string{"10.0.0.0/24"}}, "internal.corp", "10.0.0.5", true},
+		{"outside allowed range", EgressPolicy{DenyPrivate: true, AllowHosts: []string{"10.0.0.0/24"}}, "internal.corp", "10.0.1.5", false},
+		{"denied range", EgressPolicy{DenyHosts: []string{"93.184.216.0/24"}}, "example.com", "93.184.216.34", false},
+		{"deny range wins over allowed host", EgressPolicy{AllowHosts: []string{"example.com"}, DenyHosts: []string{"93.184.216.0/24"}}, "example.com", "93.184.216.34", false},
+		{"deny range wins over allowed range", EgressPolicy{DenyPrivate: true, AllowHosts: []string{"10.0.0.0/8"}, DenyHosts: []string{"10.0.0.5/32"}}, "internal.corp", "10.0.0.5", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.checkAddr(tt.host, netip.MustParseAddr(tt.addr))
+			if tt.allowed && err != nil {
+				t.Fatalf("checkAddr(%s, %s) = %v, want allowed", tt.host, tt.addr, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrEgressDenied) {
+				t.Fatalf("checkAddr(%s, %s) = %v, want ErrEgressDenied", tt.host, tt.addr, err)
+			}
+		})
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  EgressPolicy
+		host    string
+		allowed bool
+	}{
+		{"unresolved name", EgressPolicy{DenyPrivate: true}, "example.com", true},
+		{"denied name", EgressPolicy{DenyHosts: []string{"*.internal"}}, "metadata.internal", false},
+		{"deny list wins over allow list", EgressPolicy{AllowHosts: []string{"*"}, DenyHosts: []string{"evil.example"}}, "evil.example", false},
+		{"private literal", EgressPolicy{DenyPrivate: true}, "127.0.0.1", false},
+		{"allowed private literal", EgressPolicy{DenyPrivate: true, AllowHosts: []string{"127.0.0.1"}}, "127.0.0.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.checkHost(tt.host)
+			if tt.allowed && err != nil {
+				t.Fatalf("checkHost(%s) = %v, want allowed", tt.host, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrEgressDenied) {
+				t.Fatalf("checkHost(%s) = %v, want ErrEgressDenied", tt.host, err)
+			}
+		})
+	}
+}
+
+func TestProxiedRequestEgress(t *testing.T) {
+	// The proxy answers every request itself, so only the policy can fail one
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+	proxyURL := strings.Replace(proxy.URL, "127.0.0.1", "localhost", 1)
+
+	// The proxy itself is local, so it is allowed by name
+	policy := EgressPolicy{DenyPrivate: true, AllowHosts: []string{"localhost"}, DenyHosts: []string{"*.internal"}}
+	tests := []struct {
+		name    string
+		url     string
+		bypass  []string
+		allowed bool
+	}{
+		{"public address", "http://93.184.216.34/", nil, true},
+		{"metadata address", "http://169.254.169.254/latest/meta-data/", nil, false},
+		{"loopback address", "http://127.0.0.1:8080/", nil, false},
+		{"mapped private address", "http://[::ffff:10.0.0.5]/", nil, false},
+		{"denied name", "http://metadata.internal/", nil, false},
+		{"bypassed private address", "http://127.0.0.1:1/", []string{"127.0.0.1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithOptions(ClientOptions{Egress: &policy})
+			_, err := client.Execute(context.Background(), RequestConfig{
+				Method: http.MethodGet,
+				URL:    tt.url,
+				Proxy:  &ProxyConfig{URL: proxyURL, Bypass: tt.bypass},
+			})
+			if tt.allowed && err != nil {
+				t.Fatalf("Execute(%s) through a proxy = %v, want allowed", tt.url, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrEgressDenied) {
+				t.Fatalf("Execute(%s) through a proxy = %v, want ErrEgressDenied", tt.url, err)
+			}
+		})
+	}
+}
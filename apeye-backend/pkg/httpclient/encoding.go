@@ -0,0 +1,206 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// acceptEncoding is sent unless the request sets its own Accept-Encoding.
+// Setting it explicitly turns off the transport's transparent gzip handling,
+// so the compressed size is known and every encoding is decoded the same way.
+const acceptEncoding = "gzip, deflate, br, zstd"
+
+// withAcceptEncoding adds the Accept-Encoding header to config unless one is set
+func withAcceptEncoding(config RequestConfig) RequestConfig {
+	for _, header := range config.Headers {
+		if header.Enabled && strings.EqualFold(strings.TrimSpace(header.Key), "Accept-Encoding") {
+			return config
+		}
+	}
+	config.Headers = append(append([]KeyValue{}, config.Headers...), KeyValue{
+		Key:     "Accept-Encoding",
+		Value:   acceptEncoding,
+		Enabled: true,
+	})
+	return config
+}
+
+// contentEncodings returns the codings of the response in the order they were
+// applied, without identity
+func contentEncodings(header http.Header) []string {
+	var encodings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				encodings = append(encodings, coding)
+			}
+		}
+	}
+	return encodings
+}
+
+// supportedEncoding reports whether decodeReader can decode every coding
+func supportedEncoding(encodings []string) bool {
+	for _, coding := range encodings {
+		switch coding {
+		case "gzip", "x-gzip", "deflate", "br", "zstd":
+		default:
+			return false
+		}
+	}
+	return len(encodings) > 0
+}
+
+// decodeReader undoes encodings, last applied first. Close the returned
+// closer once done reading.
+func decodeReader(r io.Reader, encodings []string) (io.Reader, func(), error) {
+	var closers []func()
+	closeAll := func() {
+		for _, close := range closers {
+			close()
+		}
+	}
+
+	for i := len(encodings) - 1; i >= 0; i-- {
+		coding := encodings[i]
+		var err error
+		switch coding {
+		case "gzip", "x-gzip":
+			var gz *gzip.Reader
+			if gz, err = gzip.NewReader(r); err == nil {
+				closers = append(closers, func() { gz.Close() })
+				r = gz
+			}
+		case "deflate":
+			r, err = deflateReader(r)
+		case "br":
+			r = brotli.NewReader(r)
+		case "zstd":
+			var zr *zstd.Decoder
+			if zr, err = zstd.NewReader(r, zstd.WithDecoderConcurrency(1)); err == nil {
+				closers = append(closers, zr.Close)
+				r = zr
+			}
+		}
+		if err != nil {
+			closeAll()
+			return nil, nil, &decodeError{coding: coding, err: err}
+		}
+	}
+	return &decodeErrorReader{r: r, encodings: encodings}, closeAll, nil
+}
+
+// deflateReader reads HTTP "deflate", which is meant to be zlib but is sent
+// as raw DEFLATE by enough servers that both are accepted
+func deflateReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// decodeError is a malformed body for its content encoding
+type decodeError struct {
+	coding string
+	err    error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("failed to decode %s response: %v", e.coding, e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// decodeErrorReader reports errors from the decoders as decodeErrors
+type decodeErrorReader struct {
+	r         io.Reader
+	encodings []string
+}
+
+func (d *decodeErrorReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = &decodeError{coding: strings.Join(d.encodings, ", "), err: err}
+	}
+	return n, err
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// isTextContent reports whether a body of contentType is text that can be
+// transcoded to UTF-8
+func isTextContent(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-www-form-urlencoded", "application/xhtml+xml", "application/graphql-response+json",
+		"application/yaml", "application/x-yaml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") || strings.HasSuffix(mediaType, "+yaml")
+}
+
+// decodeCharset converts a text body to UTF-8. The charset comes from the
+// Content-Type, a byte order mark or an HTML meta tag; bodies that are
+// already valid UTF-8 without a declared charset are left alone. It returns
+// the body and the name of the charset it was decoded from.
+func decodeCharset(body []byte, contentType string) ([]byte, string) {
+	if len(body) == 0 || !isTextContent(contentType) {
+		return body, ""
+	}
+
+	_, params, _ := mime.ParseMediaType(contentType)
+	declared := params["charset"] != ""
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" || (!declared && !certain && utf8.Valid(body)) {
+		return body, "utf-8"
+	}
+	if enc == encoding.Nop {
+		return body, name
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, ""
+	}
+	return bytes.TrimPrefix(decoded, []byte("\xef\xbb\xbf")), name
+}
@@ -0,0 +1,118 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidExecutionID = errors.New("execution ID must be 1-64 letters, digits, '-' or '_'")
+	ErrExecutionIDInUse   = errors.New("an execution with this ID is already in flight")
+)
+
+var executionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Execution is a request in flight
+type Execution struct {
+	ID        string    `json:"id"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+type runningExecution struct {
+	Execution
+	owner  string
+	cancel context.CancelFunc
+}
+
+// executionKey scopes IDs to their owner so users cannot collide
+func executionKey(owner, id string) string {
+	return owner + "\x00" + id
+}
+
+// Executions tracks requests in flight so they can be listed and cancelled.
+// Each execution belongs to an owner, the user on the server; IDs are per
+// owner and only the owner sees and cancels them.
+type Executions struct {
+	mu      sync.Mutex
+	running map[string]*runningExecution
+}
+
+func NewExecutions() *Executions {
+	return &Executions{running: make(map[string]*runningExecution)}
+}
+
+// Start registers an execution of config under config.ExecutionID, or a new
+// ID when it is empty. Browsers pick the ID themselves so they can cancel
+// before the response arrives. Use the returned context for the request and
+// call done once it finishes.
+func (e *Executions) Start(ctx context.Context, owner string, config RequestConfig) (context.Context, string, func(), error) {
+	id := config.ExecutionID
+	if id == "" {
+		idBytes := make([]byte, 16)
+		if _, err := rand.Read(idBytes); err != nil {
+			return nil, "", nil, err
+		}
+		id = hex.EncodeToString(idBytes)
+	} else if !executionIDPattern.MatchString(id) {
+		return nil, "", nil, ErrInvalidExecutionID
+	}
+
+	key := executionKey(owner, id)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.running[key]; ok {
+		return nil, "", nil, ErrExecutionIDInUse
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	execution := &runningExecution{
+		Execution: Execution{ID: id, Method: config.Method, URL: config.URL, StartedAt: time.Now().UTC()},
+		owner:     owner,
+		cancel:    cancel,
+	}
+	e.running[key] = execution
+
+	done := func() {
+		cancel()
+		e.mu.Lock()
+		if e.running[key] == execution {
+			delete(e.running, key)
+		}
+		e.mu.Unlock()
+	}
+	return ctx, id, done, nil
+}
+
+// Cancel aborts an execution of owner and reports whether it was in flight
+func (e *Executions) Cancel(owner, id string) bool {
+	e.mu.Lock()
+	execution, ok := e.running[executionKey(owner, id)]
+	e.mu.Unlock()
+	if !ok {
+		return false
+	}
+	execution.cancel()
+	return true
+}
+
+// List returns the executions of owner in flight, oldest first
+func (e *Executions) List(owner string) []Execution {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	result := []Execution{}
+	for _, execution := range e.running {
+		if execution.owner == owner {
+			result = append(result, execution.Execution)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt.Before(result[j].StartedAt) })
+	return result
+}
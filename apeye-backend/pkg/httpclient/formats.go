@@ -0,0 +1,240 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// XMLNode is an element of a parsed XML response
+type XMLNode struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Text       string            `json:"text,omitempty"` // character data, trimmed
+	Children   []*XMLNode        `json:"children,omitempty"`
+}
+
+// validateXML checks that content is a single well-formed XML document
+func validateXML(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	roots := 0
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid XML: %w", err)
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if roots != 1 {
+		return errors.New("invalid XML: document must have exactly one root element")
+	}
+	return nil
+}
+
+// validateYAML checks that content parses as YAML
+func validateYAML(content string) error {
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(content), &value); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	return nil
+}
+
+// encodeMsgpack converts a JSON document to MessagePack. Whole numbers are
+// encoded as the smallest integers that fit.
+func encodeMsgpack(content string) ([]byte, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid JSON for MessagePack: %w", err)
+	}
+	if decoder.More() {
+		return nil, errors.New("invalid JSON for MessagePack: more than one value")
+	}
+
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.UseCompactInts(true)
+	if err := encoder.Encode(msgpackValue(value)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// msgpackValue replaces json.Numbers with integers or floats
+func msgpackValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = msgpackValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = msgpackValue(item)
+		}
+	}
+	return value
+}
+
+// decodeBinary decodes the base64 content of a "binary" body
+func decodeBinary(content string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid binary body: content must be base64: %w", err)
+	}
+	return data, nil
+}
+
+// responseFormat names the structured format of a response content type:
+// "json", "xml", "yaml", "msgpack" or "" for anything else
+func responseFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	switch {
+	case mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json"):
+		return "json"
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return "xml"
+	case mediaType == "application/yaml" || mediaType == "application/x-yaml" ||
+		mediaType == "text/yaml" || mediaType == "text/x-yaml" || strings.HasSuffix(mediaType, "+yaml"):
+		return "yaml"
+	case mediaType == "application/msgpack" || mediaType == "application/x-msgpack" ||
+		mediaType == "application/vnd.msgpack":
+		return "msgpack"
+	}
+	return ""
+}
+
+// parseXML parses an XML document into its root XMLNode
+func parseXML(body []byte) (*XMLNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	var root *XMLNode
+	var stack []*XMLNode
+	var text []*strings.Builder
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &XMLNode{Name: t.Name.Local, Namespace: t.Name.Space}
+			for _, attr := range t.Attr {
+				if node.Attributes == nil {
+					node.Attributes = make(map[string]string, len(t.Attr))
+				}
+				name := attr.Name.Local
+				if attr.Name.Space != "" {
+					name = attr.Name.Space + ":" + name
+				}
+				node.Attributes[name] = attr.Value
+			}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, errors.New("more than one root element")
+				}
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+			text = append(text, &strings.Builder{})
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1].Write(t)
+			}
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			node.Text = strings.TrimSpace(text[len(text)-1].String())
+			stack = stack[:len(stack)-1]
+			text = text[:len(text)-1]
+		}
+	}
+	if root == nil {
+		return nil, errors.New("no root element")
+	}
+	return root, nil
+}
+
+// parseYAML parses the first document of a YAML body
+func parseYAML(body []byte) (interface{}, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	return jsonValue(value), nil
+}
+
+// parseMsgpack parses a MessagePack body
+func parseMsgpack(body []byte) (interface{}, error) {
+	reader := bytes.NewReader(body)
+	value, err := msgpack.NewDecoder(reader).DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+	if reader.Len() > 0 {
+		return nil, errors.New("trailing data after MessagePack value")
+	}
+	return jsonValue(value), nil
+}
+
+// jsonValue makes a decoded YAML or MessagePack value encodable as JSON.
+// Maps with non-string keys get their keys formatted as strings.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = jsonValue(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = jsonValue(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonValue(item)
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,327 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	schemaCacheTTL        = 10 * time.Minute
+	maxCachedSchemas      = 64
+	maxIntrospectionBytes = 16 << 20
+)
+
+// GraphQLBody is the body of a "graphql" request. Variables is a JSON object
+// as text so placeholders can be substituted into it.
+type GraphQLBody struct {
+	Query         string `json:"query"`
+	Variables     string `json:"variables,omitempty"`
+	OperationName string `json:"operationName,omitempty"`
+}
+
+// GraphQLError is an entry of a GraphQL response's errors array
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLErrorLocation `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type GraphQLErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLSchema is the result of an introspection query
+type GraphQLSchema struct {
+	URL       string          `json:"url"`
+	Schema    json.RawMessage `json:"schema"` // data.__schema
+	FetchedAt time.Time       `json:"fetchedAt"`
+	Cached    bool            `json:"cached"`
+}
+
+// fields returns pointers to the fields that may contain variables
+func (body *GraphQLBody) fields() []*string {
+	return []*string{&body.Query, &body.Variables, &body.OperationName}
+}
+
+// variables parses the variables object; empty text means none
+func (body *GraphQLBody) variables() (map[string]interface{}, error) {
+	if strings.TrimSpace(body.Variables) == "" {
+		return nil, nil
+	}
+	var variables map[string]interface{}
+	if err := json.Unmarshal([]byte(body.Variables), &variables); err != nil {
+		return nil, fmt.Errorf("invalid GraphQL variables: %w", err)
+	}
+	return variables, nil
+}
+
+// buildGraphQLBody encodes the operation as a JSON POST body
+func buildGraphQLBody(body *GraphQLBody) (io.Reader, string, error) {
+	if body == nil || strings.TrimSpace(body.Query) == "" {
+		return nil, "", errors.New("GraphQL query is required")
+	}
+	variables, err := body.variables()
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload, err := json.Marshal(struct {
+		Query         string                 `json:"query"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+		OperationName string                 `json:"operationName,omitempty"`
+	}{body.Query, variables, body.OperationName})
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(payload), "application/json", nil
+}
+
+// graphQLParams encodes the operation as query parameters for a GET request
+func graphQLParams(body *GraphQLBody) ([]KeyValue, error) {
+	if body == nil || strings.TrimSpace(body.Query) == "" {
+		return nil, errors.New("GraphQL query is required")
+	}
+	params := []KeyValue{{Key: "query", Value: body.Query, Enabled: true}}
+	variables, err := body.variables()
+	if err != nil {
+		return nil, err
+	}
+	if variables != nil {
+		encoded, _ := json.Marshal(variables)
+		params = append(params, KeyValue{Key: "variables", Value: string(encoded), Enabled: true})
+	}
+	if body.OperationName != "" {
+		params = append(params, KeyValue{Key: "operationName", Value: body.OperationName, Enabled: true})
+	}
+	return params, nil
+}
+
+// graphQLErrors extracts the errors array from a parsed GraphQL response
+func graphQLErrors(data interface{}) []GraphQLError {
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	items, ok := object["errors"].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return nil
+	}
+	var errs []GraphQLError
+	if err := json.Unmarshal(encoded, &errs); err != nil {
+		// Not spec-shaped; keep whatever text there is
+		errs = make([]GraphQLError, 0, len(items))
+		for _, item := range items {
+			errs = append(errs, GraphQLError{Message: fmt.Sprint(item)})
+		}
+	}
+	return errs
+}
+
+// Introspect fetches the schema of the GraphQL endpoint at config.URL, sending
+// the request's headers and auth. Schemas are cached for 10 minutes per URL
+// and credentials, so different credentials never share a schema; refresh
+// skips the cache.
+func (c *Client) Introspect(ctx context.Context, config RequestConfig, refresh bool) (*GraphQLSchema, error) {
+	config.Method = http.MethodPost
+	config.Body = Body{Type: "graphql", GraphQL: &GraphQLBody{
+		Query:         introspectionQuery,
+		OperationName: "IntrospectionQuery",
+	}}
+	key := schemaCacheKey(config)
+
+	if !refresh {
+		if schema, ok := c.schemas.get(key); ok {
+			return schema, nil
+		}
+	}
+
+	resp, err := c.send(ctx, config, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxIntrospectionBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result struct {
+		Data *struct {
+			Schema json.RawMessage `json:"__schema"`
+		} `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		if resp.StatusCode >= 400 {
+			return nil, fmt.Errorf("introspection failed: %s", resp.Status)
+		}
+		return nil, fmt.Errorf("introspection failed: response is not GraphQL JSON: %w", err)
+	}
+	if result.Data == nil || len(result.Data.Schema) == 0 || string(result.Data.Schema) == "null" {
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("introspection failed: %s", result.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("introspection failed: %s", resp.Status)
+	}
+
+	schema := &GraphQLSchema{
+		URL:       config.URL,
+		Schema:    result.Data.Schema,
+		FetchedAt: time.Now().UTC(),
+	}
+	c.schemas.set(key, schema)
+	return schema, nil
+}
+
+// schemaCacheKey identifies a URL and the credentials sent to it
+func schemaCacheKey(config RequestConfig) string {
+	credentials, _ := json.Marshal(struct {
+		Params  []KeyValue
+		Headers []KeyValue
+		Auth    Auth
+	}{config.Params, config.Headers, config.Auth})
+	sum := sha256.Sum256(credentials)
+	return config.URL + "\n" + hex.EncodeToString(sum[:])
+}
+
+type cachedSchema struct {
+	schema    GraphQLSchema
+	expiresAt time.Time
+}
+
+// schemaCache holds introspection results
+type schemaCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedSchema
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{entries: make(map[string]cachedSchema)}
+}
+
+func (cache *schemaCache) get(key string) (*GraphQLSchema, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry, ok := cache.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(cache.entries, key)
+		return nil, false
+	}
+	schema := entry.schema
+	schema.Cached = true
+	return &schema, true
+}
+
+func (cache *schemaCache) set(key string, schema *GraphQLSchema) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if len(cache.entries) >= maxCachedSchemas {
+		now := time.Now()
+		for k, entry := range cache.entries {
+			if now.After(entry.expiresAt) {
+				delete(cache.entries, k)
+			}
+		}
+		if len(cache.entries) >= maxCachedSchemas {
+			cache.entries = make(map[string]cachedSchema)
+		}
+	}
+	cache.entries[key] = cachedSchema{schema: *schema, expiresAt: time.Now().Add(schemaCacheTTL)}
+}
+
+// introspectionQuery is the standard query used by GraphQL tooling
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
@@ -0,0 +1,466 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	grpcUnaryTimeout    = 30 * time.Second
+	grpcStreamTimeout   = 5 * time.Minute
+	maxGRPCMessages     = 1000
+	maxProtoFilesSize   = 4 << 20
+	maxGRPCReceiveBytes = 16 << 20
+)
+
+// metadataHeaders are set by the gRPC transport and never sent as metadata
+var metadataHeaders = map[string]bool{
+	"Content-Type":      true,
+	"User-Agent":        true,
+	"Host":              true,
+	"Connection":        true,
+	"Te":                true,
+	"Transfer-Encoding": true,
+}
+
+// GRPCConfig selects the method of a gRPC request. The target comes from the
+// request URL: grpc://host:port for plaintext, grpcs://host:port for TLS.
+// Headers and auth are sent as metadata and Body.Content holds the request
+// message as JSON. Without ProtoFiles, services are discovered with server
+// reflection.
+type GRPCConfig struct {
+	Service    string      `json:"service"` // fully qualified, e.g. "helloworld.Greeter"
+	Method     string      `json:"method"`
+	ProtoFiles []ProtoFile `json:"protoFiles,omitempty"`
+}
+
+// ProtoFile is an uploaded .proto file. Name is the path other files import
+// it by; well-known google/protobuf imports are built in.
+type ProtoFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// GRPCService is a service and its methods
+type GRPCService struct {
+	Name    string       `json:"name"`
+	Methods []GRPCMethod `json:"methods"`
+}
+
+// GRPCMethod describes a method; Example is a request message with every
+// field set to its zero value, for editing
+type GRPCMethod struct {
+	Name            string `json:"name"`
+	FullName        string `json:"fullName"`
+	InputType       string `json:"inputType"`
+	OutputType      string `json:"outputType"`
+	ClientStreaming bool   `json:"clientStreaming"`
+	ServerStreaming bool   `json:"serverStreaming"`
+	Example         string `json:"example"`
+}
+
+// GRPCResponse is the result of a gRPC call. Status is the gRPC status code,
+// StatusText its name; server streaming calls return every message received.
+type GRPCResponse struct {
+	Status     int                `json:"status"`
+	StatusText string             `json:"statusText"`
+	Message    string             `json:"message,omitempty"` // status message
+	Details    []GRPCStatusDetail `json:"details,omitempty"`
+	Headers    map[string]string  `json:"headers"`
+	Trailers   map[string]string  `json:"trailers"`
+	Messages   []json.RawMessage  `json:"messages"`
+	Truncated  bool               `json:"truncated"` // stopped after 1000 messages
+	Time       int64              `json:"time"`      // milliseconds
+	Size       int64              `json:"size"`      // bytes of JSON messages
+
+	// ID of the execution, which could be cancelled while in flight
+	ExecutionID string `json:"executionId,omitempty"`
+}
+
+// GRPCStatusDetail is an entry of the status details, left encoded
+type GRPCStatusDetail struct {
+	TypeURL string `json:"typeUrl"`
+	Value   []byte `json:"value"` // base64 in JSON
+}
+
+// GRPCServices lists the services of the target in config, from its uploaded
+// proto files or else server reflection
+func (c *Client) GRPCServices(ctx context.Context, config RequestConfig) ([]GRPCService, error) {
+	ctx, cancel := context.WithTimeout(ctx, grpcUnaryTimeout)
+	defer cancel()
+
+	var services []*desc.ServiceDescriptor
+	if config.GRPC != nil && len(config.GRPC.ProtoFiles) > 0 {
+		files, err := parseProtoFiles(config.GRPC.ProtoFiles)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			services = append(services, file.GetServices()...)
+		}
+	} else {
+		conn, callCtx, err := c.dialGRPC(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		reflection := grpcreflect.NewClientAuto(callCtx, conn)
+		defer reflection.Reset()
+		names, err := reflection.ListServices()
+		if err != nil {
+			return nil, fmt.Errorf("server reflection failed: %w", grpcError(err))
+		}
+		for _, name := range names {
+			if strings.HasPrefix(name, "grpc.reflection.") {
+				continue
+			}
+			service, err := reflection.ResolveService(name)
+			if err != nil {
+				return nil, fmt.Errorf("server reflection failed for %s: %w", name, grpcError(err))
+			}
+			services = append(services, service)
+		}
+	}
+
+	result := make([]GRPCService, 0, len(services))
+	for _, service := range services {
+		methods := make([]GRPCMethod, 0, len(service.GetMethods()))
+		for _, method := range service.GetMethods() {
+			methods = append(methods, describeMethod(method.UnwrapMethod()))
+		}
+		result = append(result, GRPCService{Name: service.GetFullyQualifiedName(), Methods: methods})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// ExecuteGRPC calls a unary or server streaming method with the JSON message
+// in config.Body.Content. A call that fails with a gRPC status still returns
+// a response carrying that status; the error is for calls that could not be
+// made at all.
+func (c *Client) ExecuteGRPC(ctx context.Context, config RequestConfig) (*GRPCResponse, error) {
+	if config.GRPC == nil || config.GRPC.Service == "" || config.GRPC.Method == "" {
+		return nil, errors.New("gRPC service and method are required")
+	}
+
+	timeout := grpcUnaryTimeout
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	conn, callCtx, err := c.dialGRPC(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	method, err := c.resolveGRPCMethod(callCtx, conn, config.GRPC)
+	if err != nil {
+		return nil, err
+	}
+	if method.IsStreamingClient() {
+		return nil, fmt.Errorf("%s is a client streaming method; only unary and server streaming calls are supported", method.FullName())
+	}
+	if method.IsStreamingServer() {
+		timeout = grpcStreamTimeout
+	}
+	callCtx, cancelTimeout := context.WithTimeout(callCtx, timeout)
+	defer cancelTimeout()
+
+	request := dynamicpb.NewMessage(method.Input())
+	if strings.TrimSpace(config.Body.Content) != "" {
+		if err := protojson.Unmarshal([]byte(config.Body.Content), request); err != nil {
+			return nil, fmt.Errorf("invalid %s message: %w", method.Input().FullName(), err)
+		}
+	}
+
+	startTime := time.Now()
+	response := &GRPCResponse{Messages: []json.RawMessage{}}
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	var header, trailer metadata.MD
+
+	record := func(message *dynamicpb.Message) error {
+		data, err := protojson.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		response.Messages = append(response.Messages, data)
+		response.Size += int64(len(data))
+		return nil
+	}
+
+	var callErr error
+	if method.IsStreamingServer() {
+		stream, err := conn.NewStream(callCtx, &grpc.StreamDesc{ServerStreams: true}, fullMethod)
+		if err == nil {
+			err = stream.SendMsg(request)
+		}
+		if err == nil {
+			err = stream.CloseSend()
+		}
+		for err == nil {
+			message := dynamicpb.NewMessage(method.Output())
+			if err = stream.RecvMsg(message); err != nil {
+				break
+			}
+			if err = record(message); err != nil {
+				return nil, err
+			}
+			if len(response.Messages) >= maxGRPCMessages {
+				response.Truncated = true
+				cancel()
+				err = nil
+				break
+			}
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		callErr = err
+		if stream != nil {
+			header, _ = stream.Header()
+			trailer = stream.Trailer()
+		}
+	} else {
+		message := dynamicpb.NewMessage(method.Output())
+		callErr = conn.Invoke(callCtx, fullMethod, request, message, grpc.Header(&header), grpc.Trailer(&trailer))
+		if callErr == nil {
+			if err := record(message); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	response.Time = time.Since(startTime).Milliseconds()
+	response.Headers = metadataMap(header)
+	response.Trailers = metadataMap(trailer)
+
+	st := status.Convert(callErr)
+	response.Status = int(st.Code())
+	response.StatusText = st.Code().String()
+	response.Message = st.Message()
+	for _, detail := range st.Proto().GetDetails() {
+		response.Details = append(response.Details, GRPCStatusDetail{TypeURL: detail.GetTypeUrl(), Value: detail.GetValue()})
+	}
+
+	return response, nil
+}
+
+// dialGRPC connects to the target of a gRPC request and returns the context to
+// call with, carrying headers and auth as outgoing metadata
+func (c *Client) dialGRPC(ctx context.Context, config RequestConfig) (*grpc.ClientConn, context.Context, error) {
+	target, err := url.Parse(config.URL)
+	if err != nil || target.Host == "" {
+		return nil, nil, fmt.Errorf("invalid gRPC URL %q, expected grpc://host:port or grpcs://host:port", config.URL)
+	}
+	if target.Scheme != "grpc" && target.Scheme != "grpcs" {
+		return nil, nil, fmt.Errorf("unsupported gRPC scheme %q, use grpc:// or grpcs://", target.Scheme)
+	}
+	address := target.Host
+	if target.Port() == "" {
+		port := "443"
+		if target.Scheme == "grpc" {
+			port = "80"
+		}
+		address = net.JoinHostPort(target.Hostname(), port)
+	}
+
+	// Under an egress policy the host name is passed through to dialContext
+	// so it resolves and checks it, instead of gRPC resolving it first
+	if c.options.Egress != nil {
+		address = "passthrough:///" + address
+	}
+
+	options := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxGRPCReceiveBytes)),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return c.dialContext(ctx, "tcp", address)
+		}),
+	}
+	if !c.options.SystemProxy {
+		options = append(options, grpc.WithNoProxy())
+	}
+	if target.Scheme == "grpcs" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		for _, cert := range config.Certificates {
+			loaded, err := loadCertificate(cert, c.options.LocalFiles)
+			if err != nil {
+				return nil, nil, err
+			}
+			if loaded.matches(target) {
+				tlsConfig = loaded.config.Clone()
+				break
+			}
+		}
+		options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		options = append(options, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	md, err := c.grpcMetadata(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := grpc.NewClient(address, options...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return conn, metadata.NewOutgoingContext(ctx, md), nil
+}
+
+// grpcMetadata builds metadata from the request's headers and auth, the same
+// way Execute sets them on an HTTP request
+func (c *Client) grpcMetadata(ctx context.Context, config RequestConfig) (metadata.MD, error) {
+	var token *OAuth2Token
+	var err error
+	if config.Auth.Type == "oauth2" {
+		if token, err = c.oauth2Token(ctx, config.tokenOwner(), config.Auth.OAuth2); err != nil {
+			return nil, err
+		}
+	}
+
+	// Signatures cover HTTP requests only
+	unsigned := config
+	if unsigned.Auth.Type == "aws-sigv4" || unsigned.Auth.Type == "hmac" || unsigned.Auth.Type == "digest" {
+		return nil, fmt.Errorf("%s auth is not supported for gRPC requests", unsigned.Auth.Type)
+	}
+	unsigned.Method = http.MethodPost
+	unsigned.Proxy = nil
+	req, err := c.newRequest(ctx, unsigned, "http://grpc.invalid/", nil, nil, "", token)
+	if err != nil {
+		return nil, err
+	}
+
+	md := metadata.MD{}
+	for key, values := range req.Header {
+		if !metadataHeaders[key] {
+			md.Append(strings.ToLower(key), values...)
+		}
+	}
+	// An API key in the query string has nowhere to go but metadata
+	if param, ok := config.Auth.apiKeyQueryParam(); ok {
+		md.Append(strings.ToLower(param.Key), param.Value)
+	}
+	return md, nil
+}
+
+// resolveGRPCMethod finds a method in the uploaded proto files or through
+// server reflection
+func (c *Client) resolveGRPCMethod(ctx context.Context, conn *grpc.ClientConn, cfg *GRPCConfig) (protoreflect.MethodDescriptor, error) {
+	var service *desc.ServiceDescriptor
+	if len(cfg.ProtoFiles) > 0 {
+		files, err := parseProtoFiles(cfg.ProtoFiles)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if service = file.FindService(cfg.Service); service != nil {
+				break
+			}
+		}
+		if service == nil {
+			return nil, fmt.Errorf("service %s not found in the uploaded proto files", cfg.Service)
+		}
+	} else {
+		reflection := grpcreflect.NewClientAuto(ctx, conn)
+		defer reflection.Reset()
+		var err error
+		if service, err = reflection.ResolveService(cfg.Service); err != nil {
+			return nil, fmt.Errorf("server reflection failed for %s: %w", cfg.Service, grpcError(err))
+		}
+	}
+
+	method := service.FindMethodByName(cfg.Method)
+	if method == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", cfg.Method, cfg.Service)
+	}
+	return method.UnwrapMethod(), nil
+}
+
+// parseProtoFiles compiles uploaded proto files; every file is parsed so
+// imports between them resolve
+func parseProtoFiles(protoFiles []ProtoFile) ([]*desc.FileDescriptor, error) {
+	contents := make(map[string]string, len(protoFiles))
+	names := make([]string, 0, len(protoFiles))
+	size := 0
+	for _, file := range protoFiles {
+		name := strings.TrimPrefix(strings.TrimSpace(file.Name), "/")
+		if name == "" || !strings.HasSuffix(name, ".proto") {
+			return nil, fmt.Errorf("invalid proto file name %q", file.Name)
+		}
+		if _, ok := contents[name]; ok {
+			return nil, fmt.Errorf("duplicate proto file %s", name)
+		}
+		size += len(file.Content)
+		contents[name] = file.Content
+		names = append(names, name)
+	}
+	if size > maxProtoFilesSize {
+		return nil, errors.New("proto files must be 4MB or smaller in total")
+	}
+
+	parser := protoparse.Parser{Accessor: protoparse.FileContentsFromMap(contents)}
+	files, err := parser.ParseFiles(names...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proto files: %w", err)
+	}
+	return files, nil
+}
+
+// describeMethod summarizes a method with an example request message
+func describeMethod(method protoreflect.MethodDescriptor) GRPCMethod {
+	example, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(dynamicpb.NewMessage(method.Input()))
+	if err != nil {
+		example = []byte("{}")
+	}
+	return GRPCMethod{
+		Name:            string(method.Name()),
+		FullName:        string(method.FullName()),
+		InputType:       string(method.Input().FullName()),
+		OutputType:      string(method.Output().FullName()),
+		ClientStreaming: method.IsStreamingClient(),
+		ServerStreaming: method.IsStreamingServer(),
+		Example:         string(example),
+	}
+}
+
+// metadataMap joins the values of each metadata key
+func metadataMap(md metadata.MD) map[string]string {
+	result := make(map[string]string, len(md))
+	for key, values := range md {
+		result[key] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+// grpcError reduces a status error to its message
+func grpcError(err error) error {
+	if st, ok := status.FromError(err); ok {
+		return fmt.Errorf("%s: %s", st.Code(), st.Message())
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHMACTemplate follows the draft HTTP Signatures format
+const defaultHMACTemplate = `Signature keyId="{keyId}",algorithm="hmac-{algorithm}",headers="{headers}",signature="{signature}"`
+
+// HMACConfig configures the hmac auth type. The signing string is one
+// "name: value" line per entry in SignedHeaders, joined with newlines.
+// Besides regular headers an entry may be "(request-target)" (lowercase
+// method and path) or "(created)" (unix time). A listed "date" or "digest"
+// header is added when the request doesn't have one.
+type HMACConfig struct {
+	Algorithm      string   `json:"algorithm"` // sha1, sha256 (default), sha384 or sha512
+	KeyID          string   `json:"keyId,omitempty"`
+	Secret         string   `json:"secret"`
+	SignedHeaders  []string `json:"signedHeaders,omitempty"`
+	HeaderName     string   `json:"headerName,omitempty"`     // defaults to Authorization
+	HeaderTemplate string   `json:"headerTemplate,omitempty"` // {keyId}, {algorithm}, {headers}, {created} and {signature} are filled in
+	Encoding       string   `json:"encoding,omitempty"`       // base64 (default) or hex
+}
+
+// fields returns pointers to every string setting, for variable substitution
+func (cfg *HMACConfig) fields() []*string {
+	return []*string{&cfg.KeyID, &cfg.Secret, &cfg.HeaderName, &cfg.HeaderTemplate}
+}
+
+// signHMAC adds the configured HMAC signature header to a fully built request
+func signHMAC(req *http.Request, payload []byte, cfg *HMACConfig, now time.Time) error {
+	if cfg == nil || cfg.Secret == "" {
+		return errors.New("hmac secret is required")
+	}
+
+	algorithm := strings.ToLower(cfg.Algorithm)
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	var newHash func() hash.Hash
+	switch algorithm {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return fmt.Errorf("unsupported hmac algorithm %q", cfg.Algorithm)
+	}
+
+	signedHeaders := cfg.SignedHeaders
+	if len(signedHeaders) == 0 {
+		signedHeaders = []string{"(request-target)", "date"}
+	}
+	created := strconv.FormatInt(now.Unix(), 10)
+
+	names := make([]string, 0, len(signedHeaders))
+	lines := make([]string, 0, len(signedHeaders))
+	for _, name := range signedHeaders {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		var value string
+		switch name {
+		case "(request-target)":
+			value = strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case "(created)":
+			value = created
+		case "host":
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		default:
+			if req.Header.Get(name) == "" {
+				switch name {
+				case "date":
+					req.Header.Set("Date", now.UTC().Format(http.TimeFormat))
+				case "digest":
+					sum := sha256.Sum256(payload)
+					req.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]))
+				}
+			}
+			value = strings.Join(req.Header.Values(name), ", ")
+		}
+
+		names = append(names, name)
+		lines = append(lines, name+": "+value)
+	}
+
+	mac := hmac.New(newHash, []byte(cfg.Secret))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	var signature string
+	if cfg.Encoding == "hex" {
+		signature = hex.EncodeToString(mac.Sum(nil))
+	} else {
+		signature = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	template := cfg.HeaderTemplate
+	if template == "" {
+		template = defaultHMACTemplate
+	}
+	headerName := cfg.HeaderName
+	if headerName == "" {
+		headerName = "Authorization"
+	}
+	req.Header.Set(headerName, strings.NewReplacer(
+		"{keyId}", cfg.KeyID,
+		"{algorithm}", algorithm,
+		"{headers}", strings.Join(names, " "),
+		"{created}", created,
+		"{signature}", signature,
+	).Replace(template))
+	return nil
+}
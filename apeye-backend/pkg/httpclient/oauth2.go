@@ -0,0 +1,446 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth 2.0 grant types
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantPassword          = "password"
+	GrantRefreshToken      = "refresh_token"
+	GrantAuthorizationCode = "authorization_code"
+)
+
+const (
+	// tokenExpiryLeeway refreshes tokens slightly before they expire
+	tokenExpiryLeeway = 30 * time.Second
+
+	// Expired tokens stay cached for staleTokenRetention so their refresh
+	// token can still be used, and at most maxCachedTokens are kept; the
+	// least recently used go first
+	staleTokenRetention = 24 * time.Hour
+	maxCachedTokens     = 10000
+)
+
+var ErrOAuth2ReauthorizationRequired = errors.New("oauth2 token expired and cannot be refreshed, authorize again")
+
+// OAuth2Config configures the oauth2 auth type
+type OAuth2Config struct {
+	GrantType    string `json:"grantType"`
+	TokenURL     string `json:"tokenUrl"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	Audience     string `json:"audience,omitempty"`
+	ClientAuth   string `json:"clientAuth,omitempty"` // "header" (HTTP basic, default) or "body"
+
+	// Password grant
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Refresh token grant, or a refresh token obtained elsewhere
+	RefreshToken string `json:"refreshToken,omitempty"`
+
+	// Authorization code grant with PKCE, see AuthorizationURL
+	AuthURL           string `json:"authUrl,omitempty"`
+	RedirectURI       string `json:"redirectUri,omitempty"`
+	AuthorizationCode string `json:"code,omitempty"`
+	CodeVerifier      string `json:"codeVerifier,omitempty"`
+}
+
+// fields returns pointers to every string setting, for variable substitution
+func (cfg *OAuth2Config) fields() []*string {
+	return []*string{
+		&cfg.TokenURL, &cfg.ClientID, &cfg.ClientSecret, &cfg.Scope, &cfg.Audience,
+		&cfg.Username, &cfg.Password, &cfg.RefreshToken,
+		&cfg.AuthURL, &cfg.RedirectURI, &cfg.AuthorizationCode, &cfg.CodeVerifier,
+	}
+}
+
+// OAuth2Token is an access token returned by a token endpoint
+type OAuth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+func (t *OAuth2Token) expired() bool {
+	return !t.ExpiresAt.IsZero() && time.Now().Add(tokenExpiryLeeway).After(t.ExpiresAt)
+}
+
+// header returns the Authorization header value for the token
+func (t *OAuth2Token) header() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// tokenCache keeps OAuth2 tokens in memory, keyed by the owner of the auth
+// settings (a saved request or collection) and the credentials themselves
+type tokenCache struct {
+	mu        sync.Mutex
+	tokens    map[string]*cachedToken
+	locks     map[string]*tokenLock
+	lastSweep time.Time
+}
+
+type cachedToken struct {
+	token    *OAuth2Token
+	lastUsed time.Time
+}
+
+// tokenLock is removed once no fetch holds or waits for it
+type tokenLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		tokens: make(map[string]*cachedToken),
+		locks:  make(map[string]*tokenLock),
+	}
+}
+
+// lock serializes token fetches for one key so concurrent requests share a token
+func (c *tokenCache) lock(key string) func() {
+	c.mu.Lock()
+	l, ok := c.locks[key]
+	if !ok {
+		l = &tokenLock{}
+		c.locks[key] = l
+	}
+	l.refs++
+	c.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		c.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(c.locks, key)
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *tokenCache) get(key string) *OAuth2Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.tokens[key]
+	if !ok {
+		return nil
+	}
+	if entry.stale(time.Now()) {
+		delete(c.tokens, key)
+		return nil
+	}
+	entry.lastUsed = time.Now()
+	return entry.token
+}
+
+func (c *tokenCache) set(key string, token *OAuth2Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[key] = &cachedToken{token: token, lastUsed: time.Now()}
+	c.evict()
+}
+
+func (c *tokenCache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, key)
+}
+
+// evict drops stale tokens, at most once a minute, and beyond
+// maxCachedTokens the least recently used ones; the caller holds mu
+func (c *tokenCache) evict() {
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= time.Minute {
+		c.lastSweep = now
+		for key, entry := range c.tokens {
+			if entry.stale(now) {
+				delete(c.tokens, key)
+			}
+		}
+	}
+	if len(c.tokens) <= maxCachedTokens {
+		return
+	}
+
+	keys := make([]string, 0, len(c.tokens))
+	for key := range c.tokens {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.tokens[keys[i]].lastUsed.Before(c.tokens[keys[j]].lastUsed)
+	})
+	for _, key := range keys[:len(keys)-maxCachedTokens] {
+		delete(c.tokens, key)
+	}
+}
+
+// stale reports whether the token expired more than staleTokenRetention ago
+func (entry *cachedToken) stale(now time.Time) bool {
+	expiresAt := entry.token.ExpiresAt
+	return !expiresAt.IsZero() && now.After(expiresAt.Add(staleTokenRetention))
+}
+
+// oauth2CacheKey derives the cache key from the token owner and every
+// credential, so a token is only reused by callers presenting the same secrets
+func oauth2CacheKey(owner string, cfg *OAuth2Config) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		owner, cfg.GrantType, cfg.TokenURL, cfg.ClientID, cfg.ClientSecret, cfg.Scope, cfg.Audience,
+		cfg.Username, cfg.Password, cfg.RefreshToken, cfg.AuthorizationCode, cfg.CodeVerifier,
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// oauth2Token returns a valid token for the config, fetching or refreshing it as needed
+func (c *Client) oauth2Token(ctx context.Context, owner string, cfg *OAuth2Config) (*OAuth2Token, error) {
+	if cfg == nil || cfg.TokenURL == "" {
+		return nil, errors.New("oauth2 token URL is required")
+	}
+
+	key := oauth2CacheKey(owner, cfg)
+	unlock := c.tokens.lock(key)
+	defer unlock()
+
+	token := c.tokens.get(key)
+	if token != nil && !token.expired() {
+		return token, nil
+	}
+
+	if token != nil && token.RefreshToken != "" {
+		refreshed, err := c.requestToken(ctx, cfg, url.Values{
+			"grant_type":    {GrantRefreshToken},
+			"refresh_token": {token.RefreshToken},
+		})
+		if err == nil {
+			c.tokens.set(key, refreshed)
+			return refreshed, nil
+		}
+		// The refresh token was revoked or has expired, so fall back to the
+		// configured grant rather than failing on it until the entry goes stale
+		c.tokens.delete(key)
+	}
+	if token != nil && cfg.GrantType == GrantAuthorizationCode {
+		// Authorization codes are single use
+		return nil, ErrOAuth2ReauthorizationRequired
+	}
+
+	token, err := c.requestToken(ctx, cfg, grantParams(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	c.tokens.set(key, token)
+	return token, nil
+}
+
+// grantParams builds the token request parameters for the configured grant
+func grantParams(cfg *OAuth2Config) url.Values {
+	params := url.Values{}
+	switch cfg.GrantType {
+	case GrantPassword:
+		params.Set("grant_type", GrantPassword)
+		params.Set("username", cfg.Username)
+		params.Set("password", cfg.Password)
+	case GrantRefreshToken:
+		params.Set("grant_type", GrantRefreshToken)
+		params.Set("refresh_token", cfg.RefreshToken)
+	case GrantAuthorizationCode:
+		params.Set("grant_type", GrantAuthorizationCode)
+		params.Set("code", cfg.AuthorizationCode)
+		if cfg.RedirectURI != "" {
+			params.Set("redirect_uri", cfg.RedirectURI)
+		}
+		if cfg.CodeVerifier != "" {
+			params.Set("code_verifier", cfg.CodeVerifier)
+		}
+	default:
+		params.Set("grant_type", GrantClientCredentials)
+	}
+
+	if cfg.Scope != "" && cfg.GrantType != GrantAuthorizationCode {
+		params.Set("scope", cfg.Scope)
+	}
+	if cfg.Audience != "" {
+		params.Set("audience", cfg.Audience)
+	}
+	return params
+}
+
+// requestToken calls the token endpoint
+func (c *Client) requestToken(ctx context.Context, cfg *OAuth2Config, params url.Values) (*OAuth2Token, error) {
+	if cfg.ClientAuth == "body" {
+		params.Set("client_id", cfg.ClientID)
+		if cfg.ClientSecret != "" {
+			params.Set("client_secret", cfg.ClientSecret)
+		}
+	} else if cfg.ClientSecret == "" {
+		// Public clients (PKCE) identify themselves in the body
+		params.Set("client_id", cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid oauth2 token URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "APEye/1.0")
+	if cfg.ClientAuth != "body" && cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2 token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oauth2 token response: %w", err)
+	}
+
+	values, err := parseTokenResponse(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 || values["error"] != "" {
+		message := values["error"]
+		if description := values["error_description"]; description != "" {
+			message += ": " + description
+		}
+		if message == "" {
+			message = resp.Status
+		}
+		return nil, fmt.Errorf("oauth2 token request failed: %s", message)
+	}
+
+	token := &OAuth2Token{
+		AccessToken:  values["access_token"],
+		TokenType:    values["token_type"],
+		RefreshToken: values["refresh_token"],
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("oauth2 token response has no access_token")
+	}
+	if seconds, err := strconv.Atoi(values["expires_in"]); err == nil && seconds > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return token, nil
+}
+
+// parseTokenResponse reads a JSON or form encoded token response into strings
+func parseTokenResponse(body []byte, contentType string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "text/plain" {
+		form, err := url.ParseQuery(string(body))
+		if err == nil {
+			for key := range form {
+				values[key] = form.Get(key)
+			}
+			return values, nil
+		}
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("invalid oauth2 token response: %w", err)
+	}
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			values[key] = v
+		case float64:
+			values[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return values, nil
+}
+
+// PKCE holds a code verifier and its S256 challenge
+type PKCE struct {
+	Verifier  string `json:"codeVerifier"`
+	Challenge string `json:"codeChallenge"`
+	Method    string `json:"codeChallengeMethod"`
+}
+
+// NewPKCE generates a random code verifier and its challenge
+func NewPKCE() (*PKCE, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(buf)
+	sum := sha256.Sum256([]byte(verifier))
+
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+		Method:    "S256",
+	}, nil
+}
+
+// AuthorizationURL builds the URL the user visits to start the authorization code flow
+func AuthorizationURL(cfg *OAuth2Config, state string, pkce *PKCE) (string, error) {
+	if cfg.AuthURL == "" {
+		return "", errors.New("oauth2 authorization URL is required")
+	}
+
+	authURL, err := url.Parse(cfg.AuthURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid oauth2 authorization URL: %w", err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("state", state)
+	if cfg.RedirectURI != "" {
+		query.Set("redirect_uri", cfg.RedirectURI)
+	}
+	if cfg.Scope != "" {
+		query.Set("scope", cfg.Scope)
+	}
+	if cfg.Audience != "" {
+		query.Set("audience", cfg.Audience)
+	}
+	if pkce != nil {
+		query.Set("code_challenge", pkce.Challenge)
+		query.Set("code_challenge_method", pkce.Method)
+	}
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOAuth2TokenRejectedRefresh(t *testing.T) {
+	var grants []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		grant := r.PostForm.Get("grant_type")
+		grants = append(grants, grant)
+
+		w.Header().Set("Content-Type", "application/json")
+		if grant == GrantRefreshToken {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant","error_description":"refresh token revoked"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"fresh","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		grantType  string
+		wantGrants []string
+		wantErr    error
+	}{
+		{"client credentials", GrantClientCredentials, []string{GrantRefreshToken, GrantClientCredentials}, nil},
+		{"password", GrantPassword, []string{GrantRefreshToken, GrantPassword}, nil},
+		{"authorization code", GrantAuthorizationCode, []string{GrantRefreshToken}, ErrOAuth2ReauthorizationRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grants = nil
+			client := NewClient()
+			cfg := &OAuth2Config{GrantType: tt.grantType, TokenURL: server.URL, ClientID: "client", ClientSecret: "secret"}
+			key := oauth2CacheKey("owner", cfg)
+			client.tokens.set(key, &OAuth2Token{
+				AccessToken:  "expired",
+				RefreshToken: "revoked",
+				ExpiresAt:    time.Now().Add(-time.Minute),
+			})
+
+			token, err := client.oauth2Token(context.Background(), "owner", cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("oauth2Token() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && token.AccessToken != "fresh" {
+				t.Fatalf("oauth2Token() access token = %q, want %q", token.AccessToken, "fresh")
+			}
+			if len(grants) != len(tt.wantGrants) {
+				t.Fatalf("token endpoint grants = %v, want %v", grants, tt.wantGrants)
+			}
+			for i := range grants {
+				if grants[i] != tt.wantGrants[i] {
+					t.Fatalf("token endpoint grants = %v, want %v", grants, tt.wantGrants)
+				}
+			}
+
+			// The rejected refresh token is not tried again
+			if cached := client.tokens.get(key); cached != nil && cached.RefreshToken == "revoked" {
+				t.Fatal("token with the rejected refresh token is still cached")
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ProxyConfig routes requests through an HTTP, HTTPS or SOCKS5 proxy
+type ProxyConfig struct {
+	URL      string   `json:"url"` // e.g. http://proxy:3128, https://proxy:443 or socks5://proxy:1080
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	Bypass   []string `json:"bypass,omitempty"` // hosts sent directly: "localhost", "*.corp", ".corp" or CIDR ranges
+}
+
+// fields returns pointers to every string setting, for variable substitution
+func (p *ProxyConfig) fields() []*string {
+	return []*string{&p.URL, &p.Username, &p.Password}
+}
+
+// proxyContextKey carries a request's proxy through the shared transport
+type proxyContextKey struct{}
+
+// ValidateProxy checks a proxy configuration
+func ValidateProxy(proxy ProxyConfig) error {
+	_, err := proxy.proxyURL()
+	return err
+}
+
+// proxyURL returns the proxy URL with credentials
+func (p *ProxyConfig) proxyURL() (*url.URL, error) {
+	raw := strings.TrimSpace(p.URL)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", p.URL)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", u.Scheme)
+	}
+
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return u, nil
+}
+
+// bypassed reports whether the host is on the bypass list
+func (p *ProxyConfig) bypassed(host string) bool {
+	return matchHosts(p.Bypass, host)
+}
+
+// matchHosts reports whether host matches one of entries: "*", an exact
+// host, a domain and its subdomains as ".corp", a glob such as "*.corp" or,
+// for IP addresses, a CIDR range
+func matchHosts(entries []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*" || entry == host:
+			return true
+		case strings.HasPrefix(entry, "."):
+			if strings.HasSuffix(host, entry) || host == entry[1:] {
+				return true
+			}
+		case strings.Contains(entry, "/"):
+			if _, network, err := net.ParseCIDR(entry); err == nil && ip != nil && network.Contains(ip) {
+				return true
+			}
+		default:
+			if ok, _ := path.Match(entry, host); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withProxy attaches a request's proxy settings to its context
+func withProxy(req *http.Request, proxy *ProxyConfig) (*http.Request, error) {
+	if proxy == nil || strings.TrimSpace(proxy.URL) == "" {
+		return req, nil
+	}
+	if _, err := proxy.proxyURL(); err != nil {
+		return nil, err
+	}
+	return req.WithContext(context.WithValue(req.Context(), proxyContextKey{}, proxy)), nil
+}
+
+// proxyFor is the transport's Proxy func. A request's own proxy wins,
+// otherwise the system proxy environment variables are used when enabled.
+// Proxied requests are checked against the egress policy here, since the
+// connection is made to the proxy.
+func (c *Client) proxyFor(req *http.Request) (*url.URL, error) {
+	proxyURL, err := c.requestProxy(req)
+	if err != nil || proxyURL == nil || c.options.Egress == nil {
+		return proxyURL, err
+	}
+	if err := c.options.Egress.checkProxied(req.Context(), req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return proxyURL, nil
+}
+
+// requestProxy returns the proxy a request goes through, or nil to connect directly
+func (c *Client) requestProxy(req *http.Request) (*url.URL, error) {
+	if proxy, ok := req.Context().Value(proxyContextKey{}).(*ProxyConfig); ok {
+		if proxy.bypassed(req.URL.Hostname()) {
+			return nil, nil
+		}
+		return proxy.proxyURL()
+	}
+	if c.options.SystemProxy {
+		return http.ProxyFromEnvironment(req)
+	}
+	return nil, nil
+}
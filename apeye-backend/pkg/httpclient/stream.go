@@ -0,0 +1,247 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxStreamDuration bounds a streaming request
+	maxStreamDuration = 30 * time.Minute
+
+	// maxStreamTranscript is how much of a streamed body is kept in the result
+	maxStreamTranscript = 1 << 20
+
+	streamChunkSize = 32 << 10
+)
+
+// Stream event types
+const (
+	StreamResponse = "response" // status and headers, sent first
+	StreamChunk    = "chunk"    // raw body bytes
+	StreamEvent    = "event"    // a parsed Server-Sent Event
+)
+
+// StreamMessage is forwarded to the caller while a response streams in
+type StreamMessage struct {
+	Type string `json:"type"`
+	Time int64  `json:"time"` // milliseconds since the request started
+
+	// StreamResponse
+	Status     int               `json:"status,omitempty"`
+	StatusText string            `json:"statusText,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+
+	// StreamChunk and StreamEvent
+	Data     string `json:"data,omitempty"`
+	Encoding string `json:"encoding,omitempty"` // "base64" when a chunk is not UTF-8 text
+	Event    string `json:"event,omitempty"`    // SSE event name
+	ID       string `json:"id,omitempty"`       // SSE event id
+}
+
+// StreamResult summarizes a streamed response. Data holds the body as text,
+// cut off after maxStreamTranscript bytes.
+type StreamResult struct {
+	Response
+	Chunks    int    `json:"chunks"`
+	Events    int    `json:"events"`
+	Truncated bool   `json:"truncated"`
+	Cancelled bool   `json:"cancelled"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ExecuteStream performs the request and passes the response to emit as it
+// arrives: Server-Sent Events (text/event-stream) one event at a time, any
+// other body in chunks. Cancelling ctx or returning an error from emit stops
+// the stream; the summary is still returned.
+func (c *Client) ExecuteStream(ctx context.Context, config RequestConfig, emit func(StreamMessage) error) (*StreamResult, error) {
+	startTime := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, maxStreamDuration)
+	defer cancel()
+
+	resp, err := c.send(ctx, withAcceptEncoding(config), true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	wire := &countingReader{r: resp.Body}
+
+	elapsed := func() int64 { return time.Since(startTime).Milliseconds() }
+	result := &StreamResult{
+		Response: Response{
+			Status:     resp.StatusCode,
+			StatusText: resp.Status,
+			Headers:    responseHeaders(resp.Header),
+
+			ContentEncoding: strings.Join(contentEncodings(resp.Header), ", "),
+		},
+	}
+
+	var transcript strings.Builder
+	record := func(data string) {
+		result.DecompressedSize += int64(len(data))
+		if room := maxStreamTranscript - transcript.Len(); room < len(data) {
+			// Cut at a character boundary so the transcript stays valid UTF-8
+			room = max(room, 0)
+			for room > 0 && !utf8.RuneStart(data[room]) {
+				room--
+			}
+			data = data[:room]
+			result.Truncated = true
+		}
+		transcript.WriteString(data)
+	}
+
+	err = emit(StreamMessage{
+		Type:       StreamResponse,
+		Time:       elapsed(),
+		Status:     result.Status,
+		StatusText: result.StatusText,
+		Headers:    result.Headers,
+	})
+	// Decoders read ahead, so set them up once the response has been emitted.
+	// Empty bodies have nothing to decode.
+	buffered := bufio.NewReader(wire)
+	var body io.Reader = buffered
+	if err == nil && supportedEncoding(contentEncodings(resp.Header)) {
+		if _, peekErr := buffered.Peek(1); peekErr != nil {
+			err = peekErr
+		}
+	}
+	if err == nil && supportedEncoding(contentEncodings(resp.Header)) {
+		var closeDecoder func()
+		body, closeDecoder, err = decodeReader(buffered, contentEncodings(resp.Header))
+		if err == nil {
+			defer closeDecoder()
+		}
+	}
+	if err == nil {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if mediaType == "text/event-stream" {
+			err = readEvents(body, func(event sseEvent) error {
+				result.Events++
+				record(event.raw)
+				return emit(StreamMessage{Type: StreamEvent, Time: elapsed(), Data: event.data, Event: event.name, ID: event.id})
+			})
+		} else {
+			buf := make([]byte, streamChunkSize)
+			var pending []byte
+			for err == nil {
+				var n int
+				n, err = body.Read(buf)
+				chunk := append(pending, buf[:n]...)
+				pending = nil
+				if err == nil {
+					// Hold back a character split across reads so text chunks stay valid UTF-8
+					chunk, pending = splitIncompleteRune(chunk)
+				}
+				if len(chunk) > 0 {
+					result.Chunks++
+					record(string(chunk))
+					message := StreamMessage{Type: StreamChunk, Time: elapsed(), Data: string(chunk)}
+					if !utf8.Valid(chunk) {
+						message.Data = base64.StdEncoding.EncodeToString(chunk)
+						message.Encoding = "base64"
+					}
+					if emitErr := emit(message); emitErr != nil {
+						err = emitErr
+					}
+				}
+			}
+		}
+	}
+
+	switch {
+	case err == nil || errors.Is(err, io.EOF):
+	case ctx.Err() != nil:
+		result.Cancelled = true
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Error = fmt.Sprintf("stream stopped after %s", maxStreamDuration)
+		}
+	default:
+		result.Cancelled = true
+		result.Error = err.Error()
+	}
+
+	result.Data = transcript.String()
+	result.CompressedSize = wire.n
+	result.Time = elapsed()
+	return result, nil
+}
+
+// splitIncompleteRune splits b before a UTF-8 sequence that is cut off at its end
+func splitIncompleteRune(b []byte) (complete, rest []byte) {
+	for i := len(b) - 1; i >= 0 && i > len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i], append([]byte(nil), b[i:]...)
+			}
+			break
+		}
+	}
+	return b, nil
+}
+
+// sseEvent is one dispatched Server-Sent Event
+type sseEvent struct {
+	name string
+	data string
+	id   string
+	raw  string // the lines as received, for the transcript
+}
+
+// readEvents parses a text/event-stream body per the HTML living standard
+func readEvents(body io.Reader, dispatch func(sseEvent) error) error {
+	reader := bufio.NewReader(body)
+	var event sseEvent
+	var data []string
+	var raw strings.Builder
+
+	for {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			return err
+		}
+		raw.WriteString(line)
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if len(data) > 0 || event.name != "" {
+				event.data = strings.Join(data, "\n")
+				event.raw = raw.String()
+				if err := dispatch(event); err != nil {
+					return err
+				}
+			}
+			event, data = sseEvent{id: event.id}, nil
+			raw.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.name = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			event.id = value
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitIncompleteRune(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		complete string
+		rest     string
+	}{
+		{"ascii", "abc", "abc", ""},
+		{"complete rune", "aé", "aé", ""},
+		{"cut two-byte rune", "a\xc3", "a", "\xc3"},
+		{"cut three-byte rune", "a\xe2\x9c", "a", "\xe2\x9c"},
+		{"cut four-byte rune", "a\xf0\x9f\x98", "a", "\xf0\x9f\x98"},
+		{"invalid byte", "a\xff", "a\xff", ""},
+		{"empty", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			complete, rest := splitIncompleteRune([]byte(tt.input))
+			if string(complete) != tt.complete || string(rest) != tt.rest {
+				t.Fatalf("splitIncompleteRune(%q) = %q, %q, want %q, %q", tt.input, complete, rest, tt.complete, tt.rest)
+			}
+		})
+	}
+}
+
+func TestExecuteStreamChunks(t *testing.T) {
+	binary := []byte{0x89, 'P', 'N', 'G', 0xff, 0x00}
+	// Offset by one byte so the transcript limit falls inside a character
+	text := "a" + strings.Repeat("é", maxStreamTranscript/2)
+
+	tests := []struct {
+		name          string
+		body          []byte
+		wantBase64    bool
+		wantTruncated bool
+	}{
+		{"text", []byte(text), false, true},
+		{"binary", binary, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			var received []byte
+			result, err := NewClient().ExecuteStream(context.Background(), RequestConfig{Method: http.MethodGet, URL: server.URL},
+				func(message StreamMessage) error {
+					if message.Type != StreamChunk {
+						return nil
+					}
+					data := []byte(message.Data)
+					if message.Encoding == "base64" {
+						if !tt.wantBase64 {
+							t.Fatalf("text chunk sent base64 encoded")
+						}
+						var err error
+						if data, err = base64.StdEncoding.DecodeString(message.Data); err != nil {
+							t.Fatal(err)
+						}
+					} else if tt.wantBase64 {
+						t.Fatalf("binary chunk %q sent as text", message.Data)
+					}
+					received = append(received, data...)
+					return nil
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(received, tt.body) {
+				t.Fatalf("chunks carried %d bytes, want the %d byte body", len(received), len(tt.body))
+			}
+			if result.Truncated != tt.wantTruncated {
+				t.Fatalf("Truncated = %v, want %v", result.Truncated, tt.wantTruncated)
+			}
+			if transcript, _ := result.Data.(string); !tt.wantBase64 && !utf8.ValidString(transcript) {
+				t.Fatal("transcript is not valid UTF-8")
+			}
+		})
+	}
+}
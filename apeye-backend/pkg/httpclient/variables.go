@@ -0,0 +1,303 @@
+package httpclient
+
+import (
+	"regexp"
+	"strings"
+)
+
+// VariableScope identifies where a variable value comes from
+type VariableScope string
+
+// Variable scopes from lowest to highest precedence. When the same name is
+// defined in several scopes the later one wins:
+//
+//	global < workspace < collection < environment < request
+//
+// so a per-request override beats the selected environment, which beats
+// collection variables, workspace globals and finally the user's own globals.
+const (
+	ScopeGlobal      VariableScope = "global"
+	ScopeWorkspace   VariableScope = "workspace"
+	ScopeCollection  VariableScope = "collection"
+	ScopeEnvironment VariableScope = "environment"
+	ScopeRequest     VariableScope = "request"
+)
+
+var scopePrecedence = map[VariableScope]int{
+	ScopeGlobal:      0,
+	ScopeWorkspace:   1,
+	ScopeCollection:  2,
+	ScopeEnvironment: 3,
+	ScopeRequest:     4,
+}
+
+// variablePattern matches {{name}} placeholders, allowing spaces around the name
+var variablePattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// VariableLayer holds the enabled variables of one scope
+type VariableLayer struct {
+	Scope   VariableScope     `json:"scope"`
+	Values  map[string]string `json:"values"`
+	Secrets []string          `json:"secrets,omitempty"` // names whose values must not be displayed
+}
+
+// ResolvedVariable describes how a placeholder was resolved
+type ResolvedVariable struct {
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Source   VariableScope `json:"source,omitempty"`
+	Secret   bool          `json:"secret"`
+	Resolved bool          `json:"resolved"`
+}
+
+// Resolver substitutes {{name}} placeholders using layered variable scopes
+type Resolver struct {
+	layers []VariableLayer
+}
+
+// NewResolver creates a resolver from layers given in any order. Layers of
+// unknown scopes are ignored.
+func NewResolver(layers ...VariableLayer) *Resolver {
+	ordered := make([]VariableLayer, 0, len(layers))
+	for _, layer := range layers {
+		if _, ok := scopePrecedence[layer.Scope]; ok {
+			ordered = append(ordered, layer)
+		}
+	}
+
+	// Stable insertion sort, there are at most a handful of layers
+	for i := 1; i < len(ordered); i++ {
+		for j := i; j > 0 && scopePrecedence[ordered[j].Scope] < scopePrecedence[ordered[j-1].Scope]; j-- {
+			ordered[j], ordered[j-1] = ordered[j-1], ordered[j]
+		}
+	}
+
+	return &Resolver{layers: ordered}
+}
+
+// RequestLayer returns the per-request overrides of a config as a layer
+func (c RequestConfig) RequestLayer() VariableLayer {
+	values := make(map[string]string, len(c.Variables))
+	for _, variable := range c.Variables {
+		if variable.Enabled && variable.Key != "" {
+			values[variable.Key] = variable.Value
+		}
+	}
+	return VariableLayer{Scope: ScopeRequest, Values: values}
+}
+
+// Lookup returns the value of a variable and the scope it was taken from
+func (r *Resolver) Lookup(name string) (string, VariableScope, bool) {
+	for i := len(r.layers) - 1; i >= 0; i-- {
+		if value, ok := r.layers[i].Values[name]; ok {
+			return value, r.layers[i].Scope, true
+		}
+	}
+	return "", "", false
+}
+
+// isSecret reports whether name is secret in the given scope
+func (r *Resolver) isSecret(name string, scope VariableScope) bool {
+	for _, layer := range r.layers {
+		if layer.Scope != scope {
+			continue
+		}
+		for _, secret := range layer.Secrets {
+			if secret == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Replace substitutes placeholders in a single string. Unknown placeholders are left untouched.
+func (r *Resolver) Replace(value string) string {
+	if !strings.Contains(value, "{{") {
+		return value
+	}
+	return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if resolved, _, ok := r.Lookup(name); ok {
+			return resolved
+		}
+		return match
+	})
+}
+
+// Apply returns a copy of config with all placeholders substituted
+func (r *Resolver) Apply(config RequestConfig) RequestConfig {
+	replaceAll := func(items []KeyValue) []KeyValue {
+		if items == nil {
+			return nil
+		}
+		result := make([]KeyValue, len(items))
+		for i, item := range items {
+			item.Key = r.Replace(item.Key)
+			item.Value = r.Replace(item.Value)
+			result[i] = item
+		}
+		return result
+	}
+
+	replacePtr := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		resolved := r.Replace(*value)
+		return &resolved
+	}
+
+	resolved := config
+	resolved.URL = r.Replace(config.URL)
+	resolved.Params = replaceAll(config.Params)
+	resolved.Headers = replaceAll(config.Headers)
+	resolved.Auth.Token = replacePtr(config.Auth.Token)
+	resolved.Auth.Username = replacePtr(config.Auth.Username)
+	resolved.Auth.Password = replacePtr(config.Auth.Password)
+	resolved.Auth.APIKey = replacePtr(config.Auth.APIKey)
+	resolved.Auth.APIValue = replacePtr(config.Auth.APIValue)
+	if config.Auth.OAuth2 != nil {
+		oauth2 := *config.Auth.OAuth2
+		for _, field := range oauth2.fields() {
+			*field = r.Replace(*field)
+		}
+		resolved.Auth.OAuth2 = &oauth2
+	}
+	if config.Auth.AWS != nil {
+		aws := *config.Auth.AWS
+		for _, field := range aws.fields() {
+			*field = r.Replace(*field)
+		}
+		resolved.Auth.AWS = &aws
+	}
+	if config.Auth.HMAC != nil {
+		hmac := *config.Auth.HMAC
+		for _, field := range hmac.fields() {
+			*field = r.Replace(*field)
+		}
+		resolved.Auth.HMAC = &hmac
+	}
+	if config.Proxy != nil {
+		proxy := *config.Proxy
+		for _, field := range proxy.fields() {
+			*field = r.Replace(*field)
+		}
+		resolved.Proxy = &proxy
+	}
+	resolved.Body.Content = r.Replace(config.Body.Content)
+	resolved.Body.FormData = replaceAll(config.Body.FormData)
+	if config.Body.GraphQL != nil {
+		graphQL := *config.Body.GraphQL
+		for _, field := range graphQL.fields() {
+			*field = r.Replace(*field)
+		}
+		resolved.Body.GraphQL = &graphQL
+	}
+	if config.Body.File != nil {
+		file := *config.Body.File
+		file.Path = r.Replace(file.Path)
+		resolved.Body.File = &file
+	}
+
+	return resolved
+}
+
+// Preview lists every placeholder used in config, in order of first use, with
+// the value it resolves to and its source scope
+func (r *Resolver) Preview(config RequestConfig) []ResolvedVariable {
+	var texts []string
+	collect := func(items []KeyValue) {
+		for _, item := range items {
+			texts = append(texts, item.Key, item.Value)
+		}
+	}
+	collectPtr := func(value *string) {
+		if value != nil {
+			texts = append(texts, *value)
+		}
+	}
+
+	texts = append(texts, config.URL)
+	collect(config.Params)
+	collect(config.Headers)
+	collectPtr(config.Auth.Token)
+	collectPtr(config.Auth.Username)
+	collectPtr(config.Auth.Password)
+	collectPtr(config.Auth.APIKey)
+	collectPtr(config.Auth.APIValue)
+	if config.Auth.OAuth2 != nil {
+		oauth2 := *config.Auth.OAuth2
+		for _, field := range oauth2.fields() {
+			texts = append(texts, *field)
+		}
+	}
+	if config.Body.File != nil {
+		texts = append(texts, config.Body.File.Path)
+	}
+	if config.Auth.AWS != nil {
+		aws := *config.Auth.AWS
+		for _, field := range aws.fields() {
+			texts = append(texts, *field)
+		}
+	}
+	if config.Body.File != nil {
+		texts = append(texts, config.Body.File.Path)
+	}
+	if config.Auth.HMAC != nil {
+		hmac := *config.Auth.HMAC
+		for _, field := range hmac.fields() {
+			texts = append(texts, *field)
+		}
+	}
+	if config.Body.File != nil {
+		texts = append(texts, config.Body.File.Path)
+	}
+	if config.Proxy != nil {
+		proxy := *config.Proxy
+		for _, field := range proxy.fields() {
+			texts = append(texts, *field)
+		}
+	}
+	if config.Body.File != nil {
+		texts = append(texts, config.Body.File.Path)
+	}
+	texts = append(texts, config.Body.Content)
+	collect(config.Body.FormData)
+	if config.Body.GraphQL != nil {
+		graphQL := *config.Body.GraphQL
+		for _, field := range graphQL.fields() {
+			texts = append(texts, *field)
+		}
+	}
+	if config.Body.File != nil {
+		texts = append(texts, config.Body.File.Path)
+	}
+
+	seen := make(map[string]bool)
+	result := []ResolvedVariable{}
+	for _, text := range texts {
+		for _, match := range variablePattern.FindAllStringSubmatch(text, -1) {
+			name := match[1]
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			value, scope, ok := r.Lookup(name)
+			result = append(result, ResolvedVariable{
+				Name:     name,
+				Value:    value,
+				Source:   scope,
+				Secret:   ok && r.isSecret(name, scope),
+				Resolved: ok,
+			})
+		}
+	}
+	return result
+}
+
+// HasUnresolvedVariables reports whether value still contains a {{name}} placeholder
+func HasUnresolvedVariables(value string) bool {
+	return variablePattern.MatchString(value)
+}
@@ -0,0 +1,321 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxTranscriptFrames and maxTranscriptSize bound the frames kept in a
+	// WebSocketTranscript, which is saved to history
+	maxTranscriptFrames = 1000
+	maxTranscriptSize   = 1 << 20
+
+	webSocketHandshakeTimeout = 30 * time.Second
+	webSocketWriteTimeout     = 10 * time.Second
+	webSocketCloseTimeout     = 5 * time.Second
+)
+
+// WebSocket frame types
+const (
+	FrameText   = "text"
+	FrameBinary = "binary"
+	FramePing   = "ping"
+	FramePong   = "pong"
+	FrameClose  = "close"
+)
+
+// handshakeHeaders are set by the WebSocket dialer itself
+var handshakeHeaders = map[string]bool{
+	"Upgrade":                  true,
+	"Connection":               true,
+	"Sec-Websocket-Key":        true,
+	"Sec-Websocket-Version":    true,
+	"Sec-Websocket-Extensions": true,
+}
+
+// WebSocketFrame is a frame sent to or received from the server. Binary
+// payloads are base64 encoded.
+type WebSocketFrame struct {
+	Direction string `json:"direction"` // "sent" or "received"
+	Type      string `json:"type"`
+	Data      string `json:"data,omitempty"`
+	Code      int    `json:"code,omitempty"` // close frames
+	Time      int64  `json:"time"`           // milliseconds since the connection opened
+}
+
+// WebSocketTranscript records a WebSocket session
+type WebSocketTranscript struct {
+	Status    int               `json:"status"`
+	Headers   map[string]string `json:"headers"`
+	Protocol  string            `json:"protocol,omitempty"`
+	Frames    []WebSocketFrame  `json:"frames"`
+	Truncated bool              `json:"truncated"`
+	Duration  int64             `json:"duration"` // milliseconds
+	Error     string            `json:"error,omitempty"`
+}
+
+// WebSocketCommand is sent by the browser to drive a relayed session
+type WebSocketCommand struct {
+	Action string `json:"action"`         // "send", "ping" or "close"
+	Type   string `json:"type,omitempty"` // text (default) or binary, for send
+	Data   string `json:"data,omitempty"` // base64 for binary frames
+	Code   int    `json:"code,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// WebSocketEvent is sent to the browser about a relayed session
+type WebSocketEvent struct {
+	Event      string               `json:"event"` // "open", "frame", "closed" or "error"
+	Frame      *WebSocketFrame      `json:"frame,omitempty"`
+	Transcript *WebSocketTranscript `json:"transcript,omitempty"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// DialWebSocket opens a WebSocket connection with the request's URL, query
+// parameters, headers, auth, client certificates, proxy and cookie jar
+func (c *Client) DialWebSocket(ctx context.Context, config RequestConfig) (*websocket.Conn, *http.Response, error) {
+	params := config.Params
+	if param, ok := config.Auth.apiKeyQueryParam(); ok {
+		params = append(append([]KeyValue{}, params...), param)
+	}
+	requestURL, err := c.buildURL(config.URL, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	var token *OAuth2Token
+	if config.Auth.Type == "oauth2" {
+		if token, err = c.oauth2Token(ctx, config.tokenOwner(), config.Auth.OAuth2); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Build the handshake like a GET so headers, auth and signatures match Execute
+	handshake := config
+	handshake.Method = http.MethodGet
+	req, err := c.newRequest(ctx, handshake, requestURL, nil, nil, "", token)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	for key, values := range req.Header {
+		if !handshakeHeaders[http.CanonicalHeaderKey(key)] {
+			header[key] = values
+		}
+	}
+
+	dialer := &websocket.Dialer{
+		Proxy:            c.proxyFor,
+		NetDialContext:   c.dialContext,
+		HandshakeTimeout: webSocketHandshakeTimeout,
+		Jar:              config.Jar,
+	}
+	for _, cert := range config.Certificates {
+		loaded, err := loadCertificate(cert, c.options.LocalFiles)
+		if err != nil {
+			return nil, nil, err
+		}
+		if loaded.matches(req.URL) {
+			dialer.TLSClientConfig = loaded.config
+			break
+		}
+	}
+
+	conn, resp, err := dialer.DialContext(req.Context(), requestURL, header)
+	if err != nil {
+		if resp != nil {
+			return nil, resp, fmt.Errorf("websocket handshake failed: %s", resp.Status)
+		}
+		return nil, nil, fmt.Errorf("websocket connection failed: %w", err)
+	}
+	return conn, resp, nil
+}
+
+// RelayWebSocket connects to the request's WebSocket server and relays it to
+// the browser connection: browser WebSocketCommands are sent upstream, and
+// every frame is reported back as a WebSocketEvent. Frames larger than the
+// maximum response size end the session. It returns when either side closes
+// or ctx is done, closing both connections, with the transcript.
+func (c *Client) RelayWebSocket(ctx context.Context, config RequestConfig, browser *websocket.Conn) (*WebSocketTranscript, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var browserMu sync.Mutex
+	notify := func(event WebSocketEvent) {
+		browserMu.Lock()
+		defer browserMu.Unlock()
+		browser.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+		browser.WriteJSON(event)
+	}
+	defer browser.Close()
+
+	upstream, resp, err := c.DialWebSocket(ctx, config)
+	if err != nil {
+		notify(WebSocketEvent{Event: "error", Error: err.Error()})
+		return nil, err
+	}
+	defer upstream.Close()
+
+	maxSize := c.maxResponseSize()
+	upstream.SetReadLimit(maxSize)
+	browser.SetReadLimit(maxSize)
+	tooLarge := fmt.Errorf("%w of %d bytes", ErrResponseTooLarge, maxSize)
+
+	startTime := time.Now()
+	transcript := &WebSocketTranscript{
+		Status:   resp.StatusCode,
+		Headers:  responseHeaders(resp.Header),
+		Protocol: upstream.Subprotocol(),
+		Frames:   []WebSocketFrame{},
+	}
+	var transcriptMu sync.Mutex
+	var transcriptSize int
+	record := func(frame WebSocketFrame) {
+		frame.Time = time.Since(startTime).Milliseconds()
+		transcriptMu.Lock()
+		// The transcript keeps the frames up to the first one that doesn't fit
+		if !transcript.Truncated && len(transcript.Frames) < maxTranscriptFrames &&
+			transcriptSize+len(frame.Data) <= maxTranscriptSize {
+			transcript.Frames = append(transcript.Frames, frame)
+			transcriptSize += len(frame.Data)
+		} else {
+			transcript.Truncated = true
+		}
+		transcriptMu.Unlock()
+		notify(WebSocketEvent{Event: "frame", Frame: &frame})
+	}
+	fail := func(err error) {
+		transcriptMu.Lock()
+		if transcript.Error == "" {
+			transcript.Error = err.Error()
+		}
+		transcriptMu.Unlock()
+	}
+
+	notify(WebSocketEvent{Event: "open", Transcript: &WebSocketTranscript{
+		Status:   transcript.Status,
+		Headers:  transcript.Headers,
+		Protocol: transcript.Protocol,
+	}})
+
+	upstream.SetPingHandler(func(data string) error {
+		record(WebSocketFrame{Direction: "received", Type: FramePing, Data: data})
+		err := upstream.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(webSocketWriteTimeout))
+		if err == nil {
+			record(WebSocketFrame{Direction: "sent", Type: FramePong, Data: data})
+		} else if errors.Is(err, websocket.ErrCloseSent) {
+			// Keep reading until the server answers our close frame
+			return nil
+		}
+		return err
+	})
+	upstream.SetPongHandler(func(data string) error {
+		record(WebSocketFrame{Direction: "received", Type: FramePong, Data: data})
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// Server to browser
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		for {
+			messageType, data, err := upstream.ReadMessage()
+			if err != nil {
+				var closeErr *websocket.CloseError
+				if errors.As(err, &closeErr) {
+					record(WebSocketFrame{Direction: "received", Type: FrameClose, Code: closeErr.Code, Data: closeErr.Text})
+				} else if errors.Is(err, websocket.ErrReadLimit) {
+					fail(tooLarge)
+				} else if ctx.Err() == nil {
+					fail(err)
+				}
+				return
+			}
+			record(newFrame("received", messageType, data))
+		}
+	}()
+
+	// Browser to server
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		for {
+			var command WebSocketCommand
+			if err := browser.ReadJSON(&command); err != nil {
+				if errors.Is(err, websocket.ErrReadLimit) {
+					fail(tooLarge)
+				}
+				return
+			}
+
+			switch command.Action {
+			case "send":
+				messageType, data := websocket.TextMessage, []byte(command.Data)
+				if command.Type == FrameBinary {
+					decoded, err := base64.StdEncoding.DecodeString(command.Data)
+					if err != nil {
+						notify(WebSocketEvent{Event: "error", Error: "binary frames must be base64 encoded"})
+						continue
+					}
+					messageType, data = websocket.BinaryMessage, decoded
+				}
+				upstream.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+				if err := upstream.WriteMessage(messageType, data); err != nil {
+					fail(err)
+					return
+				}
+				record(newFrame("sent", messageType, data))
+
+			case "ping":
+				if err := upstream.WriteControl(websocket.PingMessage, []byte(command.Data), time.Now().Add(webSocketWriteTimeout)); err != nil {
+					fail(err)
+					return
+				}
+				record(WebSocketFrame{Direction: "sent", Type: FramePing, Data: command.Data})
+
+			case "close":
+				code := command.Code
+				if code == 0 {
+					code = websocket.CloseNormalClosure
+				}
+				upstream.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, command.Reason), time.Now().Add(webSocketWriteTimeout))
+				record(WebSocketFrame{Direction: "sent", Type: FrameClose, Code: code, Data: command.Reason})
+
+				// Wait for the server's close frame, but not forever
+				time.AfterFunc(webSocketCloseTimeout, cancel)
+
+			default:
+				notify(WebSocketEvent{Event: "error", Error: fmt.Sprintf("unknown action %q", command.Action)})
+			}
+		}
+	}()
+
+	<-ctx.Done()
+	upstream.Close()
+	browser.SetReadDeadline(time.Now())
+	wg.Wait()
+
+	transcript.Duration = time.Since(startTime).Milliseconds()
+	notify(WebSocketEvent{Event: "closed", Transcript: transcript})
+	browser.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	return transcript, nil
+}
+
+// newFrame converts a data message, base64 encoding binary payloads
+func newFrame(direction string, messageType int, data []byte) WebSocketFrame {
+	if messageType == websocket.BinaryMessage {
+		return WebSocketFrame{Direction: direction, Type: FrameBinary, Data: base64.StdEncoding.EncodeToString(data)}
+	}
+	return WebSocketFrame{Direction: direction, Type: FrameText, Data: string(data)}
+}
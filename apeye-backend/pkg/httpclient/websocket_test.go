@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestRelayWebSocketReadLimit(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("small"))
+		conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 2048)))
+		conn.ReadMessage()
+	}))
+	defer upstream.Close()
+
+	client := NewClientWithOptions(ClientOptions{MaxResponseSize: 1024})
+	transcripts := make(chan *WebSocketTranscript, 1)
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		browser, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		config := RequestConfig{URL: "ws" + strings.TrimPrefix(upstream.URL, "http")}
+		transcript, err := client.RelayWebSocket(r.Context(), config, browser)
+		if err != nil {
+			t.Error(err)
+		}
+		transcripts <- transcript
+	}))
+	defer relay.Close()
+
+	browser, _, err := websocket.DefaultDialer.DialContext(context.Background(), "ws"+strings.TrimPrefix(relay.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer browser.Close()
+	for {
+		if _, _, err := browser.ReadMessage(); err != nil {
+			break
+		}
+	}
+
+	transcript := <-transcripts
+	if !strings.Contains(transcript.Error, ErrResponseTooLarge.Error()) {
+		t.Fatalf("transcript error = %q, want %q", transcript.Error, ErrResponseTooLarge)
+	}
+	if len(transcript.Frames) != 1 || transcript.Frames[0].Data != "small" {
+		t.Fatalf("transcript frames = %+v, want only the small frame", transcript.Frames)
+	}
+}
@@ -0,0 +1,136 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ciphertextPrefix marks values produced by this package so they are never
+// mistaken for plaintext
+const ciphertextPrefix = "enc:v1:"
+
+var (
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	ErrMissingMasterKey  = errors.New("master key is required")
+)
+
+// Keyring implements envelope encryption: every owner of secrets (an environment)
+// gets its own random data key, and data keys are stored wrapped with the
+// server master key. Rotating the master key only requires re-wrapping data keys.
+type Keyring struct {
+	masterKey []byte
+}
+
+// NewKeyring creates a keyring from the configured master key. A base64 encoded
+// 32 byte key is used as-is, anything else is treated as a passphrase and
+// stretched with SHA-256.
+func NewKeyring(masterKey string) (*Keyring, error) {
+	masterKey = strings.TrimSpace(masterKey)
+	if masterKey == "" {
+		return nil, ErrMissingMasterKey
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(masterKey); err == nil && len(decoded) == 32 {
+		return &Keyring{masterKey: decoded}, nil
+	}
+
+	sum := sha256.Sum256([]byte(masterKey))
+	return &Keyring{masterKey: sum[:]}, nil
+}
+
+// GenerateDataKey creates a new random data key and returns it together with
+// its wrapped (master key encrypted) form for storage
+func (k *Keyring) GenerateDataKey() ([]byte, string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	wrapped, err := seal(k.masterKey, dataKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return dataKey, wrapped, nil
+}
+
+// UnwrapDataKey decrypts a stored data key with the master key
+func (k *Keyring) UnwrapDataKey(wrapped string) ([]byte, error) {
+	return open(k.masterKey, wrapped)
+}
+
+// Encrypt encrypts a value with a data key
+func Encrypt(dataKey []byte, plaintext string) (string, error) {
+	return seal(dataKey, []byte(plaintext))
+}
+
+// Decrypt decrypts a value produced by Encrypt
+func Decrypt(dataKey []byte, ciphertext string) (string, error) {
+	plaintext, err := open(dataKey, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value looks like a ciphertext from this package
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ciphertextPrefix)
+}
+
+// seal encrypts with AES-256-GCM and encodes nonce+ciphertext as prefixed base64
+func seal(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return ciphertextPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func open(key []byte, ciphertext string) ([]byte, error) {
+	if !IsEncrypted(ciphertext) {
+		return nil, ErrInvalidCiphertext
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, ciphertextPrefix))
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name      string
+		masterKey string
+		wantErr   error
+	}{
+		{"passphrase", "correct horse battery staple", nil},
+		{"base64 key", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)), nil},
+		{"empty", "", ErrMissingMasterKey},
+		{"whitespace", "  \n", ErrMissingMasterKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.masterKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewKeyring() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDataKeyRoundTrip(t *testing.T) {
+	keyring, err := NewKeyring("master key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataKey, wrapped, err := keyring.GenerateDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(wrapped) || strings.Contains(wrapped, string(dataKey)) {
+		t.Fatalf("wrapped data key %q is not a ciphertext", wrapped)
+	}
+
+	unwrapped, err := keyring.UnwrapDataKey(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		t.Fatal("unwrapped data key differs from the generated one")
+	}
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	keyring, err := NewKeyring("master key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey, _, err := keyring.GenerateDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, plaintext := range []string{"", "s3cr3t", "ünïcødé ✓", strings.Repeat("x", 4096)} {
+		ciphertext, err := Encrypt(dataKey, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(ciphertext) || (plaintext != "" && strings.Contains(ciphertext, plaintext)) {
+			t.Fatalf("Encrypt(%q) = %q, not a ciphertext", plaintext, ciphertext)
+		}
+
+		decrypted, err := Decrypt(dataKey, ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decrypted != plaintext {
+			t.Fatalf("Decrypt() = %q, want %q", decrypted, plaintext)
+		}
+	}
+}
+
+func TestWrongKeyFails(t *testing.T) {
+	keyring, err := NewKeyring("master key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKeyring, err := NewKeyring("another master key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataKey, wrapped, err := keyring.GenerateDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := otherKeyring.UnwrapDataKey(wrapped); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Fatalf("UnwrapDataKey() with the wrong master key error = %v, want ErrInvalidCiphertext", err)
+	}
+
+	otherDataKey, _, err := keyring.GenerateDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := Encrypt(dataKey, "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(otherDataKey, ciphertext); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Fatalf("Decrypt() with the wrong data key error = %v, want ErrInvalidCiphertext", err)
+	}
+}
+
+func TestOpenRejectsInvalidCiphertext(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{1}, 32)
+	ciphertext, err := Encrypt(dataKey, "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, ciphertextPrefix))
+	raw[len(raw)-1] ^= 0xff
+	tampered := ciphertextPrefix + base64.StdEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name       string
+		ciphertext string
+	}{
+		{"plaintext", "s3cr3t"},
+		{"bad base64", ciphertextPrefix + "!!!"},
+		{"too short", ciphertextPrefix + base64.StdEncoding.EncodeToString([]byte("short"))},
+		{"tampered", tampered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decrypt(dataKey, tt.ciphertext); !errors.Is(err, ErrInvalidCiphertext) {
+				t.Fatalf("Decrypt() error = %v, want ErrInvalidCiphertext", err)
+			}
+		})
+	}
+}
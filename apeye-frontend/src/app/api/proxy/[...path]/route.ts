@@ -4,7 +4,7 @@ import { cookies } from 'next/headers';
 const BACKEND_URL = process.env.BACKEND_URL || process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api';
 
 async function proxyRequest(request: NextRequest, path: string) {
-  const url = `${BACKEND_URL}/${path}`;
+  const url = `${BACKEND_URL}/${path}${request.nextUrl.search}`;
   
   // Get all cookies and forward them
   const cookieStore = await cookies();
@@ -31,9 +31,11 @@ async function proxyRequest(request: NextRequest, path: string) {
   }
 
   // Build fetch options
+  // Aborting the browser request (e.g. cancelling a stream) aborts the backend call
   const fetchOptions: RequestInit = {
     method: request.method,
     headers,
+    signal: request.signal,
   };
 
   // Add body for non-GET requests
@@ -57,6 +59,17 @@ async function proxyRequest(request: NextRequest, path: string) {
     
     // Get response data
     const resContentType = response.headers.get('Content-Type') || '';
+
+    // Pass Server-Sent Events through as they arrive
+    if (resContentType.includes('text/event-stream')) {
+      return new NextResponse(response.body, {
+        status: response.status,
+        headers: {
+          'Content-Type': resContentType,
+          'Cache-Control': 'no-cache',
+        },
+      });
+    }
     
     if (resContentType.includes('application/json')) {
       const data = await response.json();
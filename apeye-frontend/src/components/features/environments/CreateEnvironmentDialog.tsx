@@ -34,7 +34,7 @@ export default function CreateEnvironmentDialog({
 
     setIsSubmitting(true);
     try {
-      await createEnvironment(name, {});
+      await createEnvironment(name, []);
       setName('');
       onOpenChange(false);
     } catch (error) {
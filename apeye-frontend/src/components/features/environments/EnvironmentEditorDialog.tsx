@@ -14,7 +14,7 @@ import {
 import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
 import { useEnvironmentsStore } from '@/stores/environmentsStore';
-import type { Environment } from '@/types';
+import type { Environment, EnvironmentVariable, VariableType } from '@/types';
 
 interface EnvironmentEditorDialogProps {
   open: boolean;
@@ -22,12 +22,21 @@ interface EnvironmentEditorDialogProps {
   environment: Environment | null;
 }
 
-interface Variable {
+interface Variable extends EnvironmentVariable {
   id: string;
-  key: string;
-  value: string;
 }
 
+const VARIABLE_TYPES: VariableType[] = ['string', 'secret', 'number', 'boolean', 'json'];
+
+const emptyVariable = (): Variable => ({
+  id: crypto.randomUUID(),
+  key: '',
+  value: '',
+  type: 'string',
+  description: '',
+  enabled: true,
+});
+
 export default function EnvironmentEditorDialog({
   open,
   onOpenChange,
@@ -42,26 +51,28 @@ export default function EnvironmentEditorDialog({
   useEffect(() => {
     if (environment) {
       setName(environment.name);
-      // Convert Record<string, string> to Variable[]
-      const vars = Object.entries(environment.variables || {}).map(([key, value]) => ({
+      const vars = (environment.variables || []).map((variable) => ({
+        ...variable,
         id: crypto.randomUUID(),
-        key,
-        value,
       }));
       // Add an empty row if no variables
-      setVariables(vars.length > 0 ? vars : [{ id: crypto.randomUUID(), key: '', value: '' }]);
+      setVariables(vars.length > 0 ? vars : [emptyVariable()]);
     }
   }, [environment]);
 
   const handleAddVariable = () => {
-    setVariables([...variables, { id: crypto.randomUUID(), key: '', value: '' }]);
+    setVariables([...variables, emptyVariable()]);
   };
 
   const handleRemoveVariable = (id: string) => {
     setVariables(variables.filter((v) => v.id !== id));
   };
 
-  const handleVariableChange = (id: string, field: 'key' | 'value', newValue: string) => {
+  const handleVariableChange = <K extends keyof EnvironmentVariable>(
+    id: string,
+    field: K,
+    newValue: EnvironmentVariable[K]
+  ) => {
     setVariables(
       variables.map((v) => (v.id === id ? { ...v, [field]: newValue } : v))
     );
@@ -74,17 +85,12 @@ export default function EnvironmentEditorDialog({
 
     setIsSubmitting(true);
     try {
-      // Convert Variable[] back to Record<string, string>
-      // Trim both keys and values to prevent whitespace issues
-      const variablesRecord: Record<string, string> = {};
-      variables.forEach((v) => {
-        const key = v.key.trim();
-        if (key) {
-          variablesRecord[key] = v.value.trim();
-        }
-      });
-
-      await updateEnvironment(environment.id, { name: name.trim(), variables: variablesRecord });
+      // Drop empty rows and trim keys and values to prevent whitespace issues
+      const entries: EnvironmentVariable[] = variables
+        .filter((v) => v.key.trim())
+        .map(({ id: _id, ...v }) => ({ ...v, key: v.key.trim(), value: v.value.trim() }));
+
+      await updateEnvironment(environment.id, { name: name.trim(), variables: entries });
       onOpenChange(false);
     } catch (error) {
       // Error handled in store
@@ -138,32 +144,66 @@ export default function EnvironmentEditorDialog({
                   </p>
                 ) : (
                   variables.map((variable) => (
-                    <div key={variable.id} className="flex items-center gap-2">
-                      <Input
-                        placeholder="Variable name"
-                        value={variable.key}
-                        onChange={(e) =>
-                          handleVariableChange(variable.id, 'key', e.target.value)
-                        }
-                        className="font-mono text-sm"
-                      />
+                    <div key={variable.id} className="space-y-1">
+                      <div className="flex items-center gap-2">
+                        <input
+                          type="checkbox"
+                          checked={variable.enabled}
+                          onChange={(e) =>
+                            handleVariableChange(variable.id, 'enabled', e.target.checked)
+                          }
+                          className="h-4 w-4 shrink-0"
+                          aria-label="Enabled"
+                        />
+                        <Input
+                          placeholder="Variable name"
+                          value={variable.key}
+                          onChange={(e) =>
+                            handleVariableChange(variable.id, 'key', e.target.value)
+                          }
+                          className="font-mono text-sm"
+                        />
+                        <Input
+                          placeholder="Value"
+                          type={variable.type === 'secret' ? 'password' : 'text'}
+                          value={variable.value}
+                          onChange={(e) =>
+                            handleVariableChange(variable.id, 'value', e.target.value)
+                          }
+                          className="font-mono text-sm"
+                        />
+                        <select
+                          value={variable.type}
+                          onChange={(e) =>
+                            handleVariableChange(variable.id, 'type', e.target.value as VariableType)
+                          }
+                          className="h-9 rounded-md border bg-background px-2 text-sm"
+                          aria-label="Type"
+                        >
+                          {VARIABLE_TYPES.map((type) => (
+                            <option key={type} value={type}>
+                              {type}
+                            </option>
+                          ))}
+                        </select>
+                        <Button
+                          type="button"
+                          variant="ghost"
+                          size="icon"
+                          onClick={() => handleRemoveVariable(variable.id)}
+                          className="shrink-0 text-muted-foreground hover:text-destructive"
+                        >
+                          <Trash2 className="h-4 w-4" />
+                        </Button>
+                      </div>
                       <Input
-                        placeholder="Value"
-                        value={variable.value}
+                        placeholder="Description (optional)"
+                        value={variable.description}
                         onChange={(e) =>
-                          handleVariableChange(variable.id, 'value', e.target.value)
+                          handleVariableChange(variable.id, 'description', e.target.value)
                         }
-                        className="font-mono text-sm"
+                        className="h-8 text-xs"
                       />
-                      <Button
-                        type="button"
-                        variant="ghost"
-                        size="icon"
-                        onClick={() => handleRemoveVariable(variable.id)}
-                        className="shrink-0 text-muted-foreground hover:text-destructive"
-                      >
-                        <Trash2 className="h-4 w-4" />
-                      </Button>
                     </div>
                   ))
                 )}
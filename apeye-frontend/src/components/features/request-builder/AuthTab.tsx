@@ -5,9 +5,10 @@ import { Input } from '@/components/ui/input';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
 import { useRequestStore } from '@/stores/requestStore';
 import { AUTH_TYPES } from '@/config/constants';
+import type { ApiKeyLocation } from '@/types';
 
 export default function AuthTab() {
-  const { config, setAuthType, setAuthToken, setAuthBasic, setAuthApiKey } = useRequestStore();
+  const { config, setAuthType, setAuthToken, setAuthBasic, setAuthApiKey, setAuthApiKeyLocation } = useRequestStore();
 
   return (
     <div className="space-y-4">
@@ -38,7 +39,7 @@ export default function AuthTab() {
         </div>
       )}
 
-      {config.auth.type === 'basic' && (
+      {(config.auth.type === 'basic' || config.auth.type === 'digest') && (
         <div className="space-y-4">
           <div className="space-y-2">
             <Label>Username</Label>
@@ -78,6 +79,22 @@ export default function AuthTab() {
               onChange={(e) => setAuthApiKey(config.auth.apiKey || '', e.target.value)}
             />
           </div>
+          <div className="space-y-2">
+            <Label>Add to</Label>
+            <Select
+              value={config.auth.addTo || 'header'}
+              onValueChange={(value) => setAuthApiKeyLocation(value as ApiKeyLocation)}
+            >
+              <SelectTrigger>
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="header">Header</SelectItem>
+                <SelectItem value="query">Query Params</SelectItem>
+                <SelectItem value="cookie">Cookie</SelectItem>
+              </SelectContent>
+            </Select>
+          </div>
         </div>
       )}
 
@@ -1,21 +1,64 @@
 'use client';
 
+import { useState } from 'react';
+import toast from 'react-hot-toast';
+import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
 import { Textarea } from '@/components/ui/textarea';
 import { useRequestStore } from '@/stores/requestStore';
 import { BODY_TYPES } from '@/config/constants';
+import { blobsApi } from '@/lib/api/blobs';
 import KeyValueInput from './KeyValueInput';
 
+// Text editors for body types whose content is sent as typed, or converted
+const TEXT_BODIES: Partial<Record<string, { label: string; placeholder: string; hint?: string }>> = {
+  xml: { label: 'XML Body', placeholder: '<root><key>value</key></root>' },
+  html: { label: 'HTML Body', placeholder: '<p>Hello</p>' },
+  yaml: { label: 'YAML Body', placeholder: 'key: value' },
+  msgpack: {
+    label: 'MessagePack Body',
+    placeholder: '{"key": "value"}',
+    hint: 'Written as JSON and sent as MessagePack.',
+  },
+};
+
+type BinarySource = 'base64' | 'upload' | 'path';
+
 export default function BodyTab() {
   const {
     config,
     setBodyType,
     setBodyContent,
+    setGraphQL,
+    setBodyFile,
     addFormData,
     updateFormData,
     removeFormData,
   } = useRequestStore();
+  const [uploading, setUploading] = useState(false);
+  const file = config.body.file;
+  const binarySource: BinarySource = file?.path !== undefined ? 'path' : file ? 'upload' : 'base64';
+
+  const handleBinarySource = (source: BinarySource) => {
+    if (source === 'base64') setBodyFile(undefined);
+    else if (source === 'path') setBodyFile({ path: '' });
+    else setBodyFile({});
+  };
+
+  const handleUpload = async (selected: File | undefined) => {
+    if (!selected) return;
+    setUploading(true);
+    try {
+      const blob = await blobsApi.upload(config.workspaceId || 'default', selected);
+      setBodyFile({ blobId: blob.id, name: blob.name, contentType: blob.contentType || undefined });
+      toast.success(`Uploaded ${blob.name}`);
+    } catch (error) {
+      toast.error(error instanceof Error ? error.message : 'Failed to upload file');
+    } finally {
+      setUploading(false);
+    }
+  };
 
   return (
     <div className="space-y-4">
@@ -59,6 +102,115 @@ export default function BodyTab() {
         </div>
       )}
 
+      {TEXT_BODIES[config.body.type] && (
+        <div className="space-y-2">
+          <Label>{TEXT_BODIES[config.body.type]!.label}</Label>
+          <Textarea
+            placeholder={TEXT_BODIES[config.body.type]!.placeholder}
+            value={config.body.content}
+            onChange={(e) => setBodyContent(e.target.value)}
+            className="font-mono text-sm min-h-[200px]"
+          />
+          {TEXT_BODIES[config.body.type]!.hint && (
+            <p className="text-xs text-muted-foreground">{TEXT_BODIES[config.body.type]!.hint}</p>
+          )}
+        </div>
+      )}
+
+      {config.body.type === 'binary' && (
+        <div className="space-y-4">
+          <div className="space-y-2">
+            <Label>Source</Label>
+            <Select value={binarySource} onValueChange={(value) => handleBinarySource(value as BinarySource)}>
+              <SelectTrigger>
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="base64">Base64 content</SelectItem>
+                <SelectItem value="upload">Uploaded file</SelectItem>
+                <SelectItem value="path">Local file (agent)</SelectItem>
+              </SelectContent>
+            </Select>
+          </div>
+
+          {binarySource === 'base64' && (
+            <div className="space-y-2">
+              <Label>Binary Body</Label>
+              <Textarea
+                placeholder="aGVsbG8="
+                value={config.body.content}
+                onChange={(e) => setBodyContent(e.target.value)}
+                className="font-mono text-sm min-h-[200px]"
+              />
+              <p className="text-xs text-muted-foreground">
+                Base64 encoded; the decoded bytes are sent as application/octet-stream.
+              </p>
+            </div>
+          )}
+
+          {binarySource === 'upload' && (
+            <div className="space-y-2">
+              <Label>File</Label>
+              <Input type="file" disabled={uploading} onChange={(e) => handleUpload(e.target.files?.[0])} />
+              <p className="text-xs text-muted-foreground">
+                {file?.blobId
+                  ? `Sending ${file.name}. The file is stored in the workspace and saved with the request.`
+                  : 'The file is stored in the workspace so saved requests can send it again.'}
+              </p>
+            </div>
+          )}
+
+          {binarySource === 'path' && (
+            <div className="space-y-2">
+              <Label>Path</Label>
+              <Input
+                placeholder="/home/me/upload.zip"
+                value={file?.path || ''}
+                onChange={(e) => setBodyFile({ ...file, path: e.target.value })}
+                className="font-mono"
+              />
+              <p className="text-xs text-muted-foreground">
+                Read by the local agent when the request is sent, so it must be running.
+              </p>
+            </div>
+          )}
+        </div>
+      )}
+
+      {config.body.type === 'graphql' && (
+        <div className="space-y-4">
+          <div className="space-y-2">
+            <Label>Query</Label>
+            <Textarea
+              placeholder="query { viewer { id } }"
+              value={config.body.graphql?.query || ''}
+              onChange={(e) => setGraphQL('query', e.target.value)}
+              className="font-mono text-sm min-h-[200px]"
+            />
+          </div>
+          <div className="space-y-2">
+            <Label>Variables</Label>
+            <Textarea
+              placeholder='{"id": "1"}'
+              value={config.body.graphql?.variables || ''}
+              onChange={(e) => setGraphQL('variables', e.target.value)}
+              className="font-mono text-sm min-h-[100px]"
+            />
+          </div>
+          <div className="space-y-2">
+            <Label>Operation Name</Label>
+            <Input
+              placeholder="Optional"
+              value={config.body.graphql?.operationName || ''}
+              onChange={(e) => setGraphQL('operationName', e.target.value)}
+            />
+          </div>
+          <p className="text-xs text-muted-foreground">
+            Sent as a JSON POST, or in the query string when the method is GET.
+          </p>
+        </div>
+      )}
+
       {(config.body.type === 'form-data' || config.body.type === 'x-www-form-urlencoded') && (
         <div className="space-y-2">
           <Label>Form Data</Label>
@@ -3,6 +3,7 @@
 import { useState } from 'react';
 import { Download, Copy, Check } from 'lucide-react';
 import { useRequestStore } from '@/stores/requestStore';
+import { requestsApi, runsOnAgent } from '@/lib/api/requests';
 import { Button } from '@/components/ui/button';
 import { Badge } from '@/components/ui/badge';
 import { Tabs, TabsContent, TabsList, TabsTrigger } from '@/components/ui/tabs';
@@ -22,7 +23,7 @@ import {
 import toast from 'react-hot-toast';
 
 export default function ResponseViewer() {
-  const { response, isLoading } = useRequestStore();
+  const { config, response, isLoading, inFlight, cancelRequest } = useRequestStore();
   const [activeTab, setActiveTab] = useState('body');
   const [prettyPrint, setPrettyPrint] = useState(true);
   const [bodyCopied, setBodyCopied] = useState(false);
@@ -34,6 +35,11 @@ export default function ResponseViewer() {
         <div className="text-center">
           <div className="animate-spin rounded-full h-12 w-12 border-b-2 border-primary mx-auto mb-4"></div>
           <p className="text-muted-foreground">Sending request...</p>
+          {inFlight && (
+            <Button variant="outline" size="sm" className="mt-4" onClick={cancelRequest}>
+              Cancel
+            </Button>
+          )}
         </div>
       </div>
     );
@@ -77,7 +83,8 @@ export default function ResponseViewer() {
   };
 
   const getLanguage = () => {
-    if (isJsonResponse) return 'json';
+    // XML, YAML and MessagePack responses arrive parsed into a tree
+    if (isJsonResponse || (response.data && typeof response.data === 'object')) return 'json';
     if (isXmlResponse) return 'xml';
     if (isHtmlResponse) return 'html';
     return 'text';
@@ -94,13 +101,30 @@ export default function ResponseViewer() {
     }
   };
 
-  const handleDownload = () => {
-    const body = getResponseBody();
+  const handleDownload = async () => {
     const timestamp = new Date().toISOString().replace(/[:.]/g, '-');
     const extension = isJsonResponse ? 'json' : isXmlResponse ? 'xml' : isHtmlResponse ? 'html' : 'txt';
     const filename = `response_${timestamp}.${extension}`;
-    
-    downloadFile(body, filename, contentType);
+    const treeFilename = `response_${timestamp}.json`;
+
+    // The preview of a truncated body is incomplete; fetch the full body
+    if (response.truncated && response.bodyId) {
+      try {
+        const blob = await requestsApi.downloadBody(response.bodyId, runsOnAgent(config));
+        downloadFile(blob, filename, contentType);
+        toast.success('Response downloaded');
+      } catch (error) {
+        toast.error(error instanceof Error ? error.message : 'Failed to download response');
+      }
+      return;
+    }
+
+    // Parsed XML, YAML and MessagePack trees are saved as JSON
+    if (!isJsonResponse && response.data && typeof response.data === 'object') {
+      downloadFile(getResponseBody(), treeFilename, 'application/json');
+    } else {
+      downloadFile(getResponseBody(), filename, contentType);
+    }
     toast.success('Response downloaded');
   };
 
@@ -116,8 +140,17 @@ export default function ResponseViewer() {
             <span className="font-medium text-foreground">{formatTime(response.time)}</span>
           </span>
           <span className="text-xs sm:text-sm text-muted-foreground">
-            <span className="font-medium text-foreground">{formatBytes(response.size)}</span>
+            <span className="font-medium text-foreground">{formatBytes(response.compressedSize)}</span>
+            {response.contentEncoding && (
+              <span>
+                {' '}{response.contentEncoding}
+                {response.decompressedSize > 0 && <>, {formatBytes(response.decompressedSize)} decompressed</>}
+              </span>
+            )}
           </span>
+          {response.charset && response.charset !== 'utf-8' && (
+            <span className="text-xs sm:text-sm text-muted-foreground">{response.charset}</span>
+          )}
         </div>
   
         <div className="flex items-center gap-1 sm:gap-2">
@@ -172,6 +205,41 @@ export default function ResponseViewer() {
           </TabsList>
   
           <TabsContent value="body" className="flex-1 p-2 sm:p-4 overflow-auto m-0">
+            {response.truncated && (
+              <Card className="p-2 sm:p-4 mb-2 sm:mb-4 border-warning flex items-center justify-between gap-2">
+                <p className="text-xs sm:text-sm">
+                  The response is {formatBytes(response.decompressedSize || response.compressedSize)}, too large to show in full. This is a preview of its start.
+                </p>
+                {response.bodyId && (
+                  <Button variant="outline" size="sm" onClick={handleDownload} className="text-xs sm:text-sm h-8 flex-shrink-0">
+                    <Download className="h-3 w-3 sm:h-4 sm:w-4 sm:mr-1" />
+                    <span className="hidden sm:inline">Download full body</span>
+                  </Button>
+                )}
+              </Card>
+            )}
+            {response.graphqlErrors && response.graphqlErrors.length > 0 && (
+              <Card className="p-2 sm:p-4 mb-2 sm:mb-4 border-destructive">
+                <p className="text-xs sm:text-sm font-medium text-destructive mb-2">
+                  GraphQL errors ({response.graphqlErrors.length})
+                </p>
+                <ul className="space-y-1 text-xs sm:text-sm">
+                  {response.graphqlErrors.map((error, index) => (
+                    <li key={index} className="break-all">
+                      {error.message}
+                      {error.path && (
+                        <span className="text-muted-foreground"> at {error.path.join('.')}</span>
+                      )}
+                      {error.locations?.[0] && (
+                        <span className="text-muted-foreground">
+                          {' '}(line {error.locations[0].line}, column {error.locations[0].column})
+                        </span>
+                      )}
+                    </li>
+                  ))}
+                </ul>
+              </Card>
+            )}
             {response.data ? (
               <CodeBlock
                 code={getResponseBody()}
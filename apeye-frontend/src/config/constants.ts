@@ -2,6 +2,10 @@ import type { HttpMethod, AuthType, BodyType } from '@/types';
 
 // Use proxy route to forward requests with cookies to backend
 export const API_BASE_URL = '/api/proxy';
+// WebSockets cannot go through the proxy route, so the browser connects to the backend directly
+export const WEBSOCKET_BASE_URL = new URL(
+  process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+).origin.replace(/^http/, 'ws');
 export const AGENT_BASE_URL = process.env.NEXT_PUBLIC_AGENT_URL || 'http://127.0.0.1:6363';
 export const AGENT_WINDOWS_DOWNLOAD_URL =
   process.env.NEXT_PUBLIC_AGENT_WINDOWS_DOWNLOAD_URL ||
@@ -22,6 +26,7 @@ export const AUTH_TYPES: { value: AuthType; label: string }[] = [
   { value: 'none', label: 'No Auth' },
   { value: 'bearer', label: 'Bearer Token' },
   { value: 'basic', label: 'Basic Auth' },
+  { value: 'digest', label: 'Digest Auth' },
   { value: 'api-key', label: 'API Key' },
 ];
 
@@ -31,6 +36,12 @@ export const BODY_TYPES: { value: BodyType; label: string }[] = [
   { value: 'form-data', label: 'Form Data' },
   { value: 'x-www-form-urlencoded', label: 'URL Encoded' },
   { value: 'raw', label: 'Raw' },
+  { value: 'graphql', label: 'GraphQL' },
+  { value: 'xml', label: 'XML' },
+  { value: 'html', label: 'HTML' },
+  { value: 'yaml', label: 'YAML' },
+  { value: 'msgpack', label: 'MessagePack' },
+  { value: 'binary', label: 'Binary' },
 ];
 
 export const COMMON_HEADERS = [
@@ -0,0 +1,23 @@
+import axiosInstance from './axios';
+import type { UploadedBlob } from '@/types';
+
+// Files uploaded to a workspace for binary request bodies
+export const blobsApi = {
+  list: async (workspaceId: string): Promise<UploadedBlob[]> => {
+    const response = await axiosInstance.get(`/workspaces/${workspaceId}/blobs`);
+    return response.data;
+  },
+
+  upload: async (workspaceId: string, file: File): Promise<UploadedBlob> => {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await axiosInstance.post(`/workspaces/${workspaceId}/blobs`, form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  },
+
+  delete: async (workspaceId: string, blobId: string): Promise<void> => {
+    await axiosInstance.delete(`/workspaces/${workspaceId}/blobs/${blobId}`);
+  },
+};
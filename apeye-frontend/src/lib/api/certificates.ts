@@ -0,0 +1,36 @@
+import axiosInstance from './axios';
+import type { ClientCertificate } from '@/types';
+
+export interface UploadCertificateInput {
+  name?: string;
+  hostPattern: string;
+  certificate?: File;
+  privateKey?: File;
+  ca?: File;
+}
+
+export const certificatesApi = {
+  list: async (): Promise<ClientCertificate[]> => {
+    const response = await axiosInstance.get('/certificates');
+    return response.data;
+  },
+
+  // Upload a client certificate/key pair and/or a CA bundle as PEM files
+  upload: async (input: UploadCertificateInput): Promise<ClientCertificate> => {
+    const form = new FormData();
+    form.append('hostPattern', input.hostPattern);
+    if (input.name) form.append('name', input.name);
+    if (input.certificate) form.append('certificate', input.certificate);
+    if (input.privateKey) form.append('privateKey', input.privateKey);
+    if (input.ca) form.append('ca', input.ca);
+
+    const response = await axiosInstance.post('/certificates', form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  },
+
+  delete: async (id: string): Promise<void> => {
+    await axiosInstance.delete(`/certificates/${id}`);
+  },
+};
@@ -0,0 +1,33 @@
+import axiosInstance from './axios';
+import type { Cookie, CookieInput } from '@/types';
+
+export const cookiesApi = {
+  list: async (workspaceId: string, domain?: string): Promise<Cookie[]> => {
+    const response = await axiosInstance.get(`/workspaces/${workspaceId}/cookies`, {
+      params: domain ? { domain } : undefined,
+    });
+    return response.data;
+  },
+
+  // Adds a cookie, replacing one with the same domain, path and name
+  set: async (workspaceId: string, cookie: CookieInput): Promise<Cookie> => {
+    const response = await axiosInstance.post(`/workspaces/${workspaceId}/cookies`, cookie);
+    return response.data;
+  },
+
+  update: async (workspaceId: string, cookieId: string, cookie: CookieInput): Promise<Cookie> => {
+    const response = await axiosInstance.put(`/workspaces/${workspaceId}/cookies/${cookieId}`, cookie);
+    return response.data;
+  },
+
+  delete: async (workspaceId: string, cookieId: string): Promise<void> => {
+    await axiosInstance.delete(`/workspaces/${workspaceId}/cookies/${cookieId}`);
+  },
+
+  // Clears the whole jar, or only one domain
+  clear: async (workspaceId: string, domain?: string): Promise<void> => {
+    await axiosInstance.delete(`/workspaces/${workspaceId}/cookies`, {
+      params: domain ? { domain } : undefined,
+    });
+  },
+};
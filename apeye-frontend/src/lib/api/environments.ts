@@ -1,15 +1,15 @@
 import axiosInstance from './axios';
-import type { Environment } from '@/types';
+import type { Environment, EnvironmentDiff, EnvironmentVariable } from '@/types';
 
 export interface CreateEnvironmentInput {
   workspace_id?: string;
   name: string;
-  variables?: Record<string, string>;
+  variables?: EnvironmentVariable[];
 }
 
 export interface UpdateEnvironmentInput {
   name?: string;
-  variables?: Record<string, string>;
+  variables?: EnvironmentVariable[];
 }
 
 export const environmentsApi = {
@@ -41,4 +41,35 @@ export const environmentsApi = {
   delete: async (id: string): Promise<void> => {
     await axiosInstance.delete(`/environments/${id}`);
   },
+
+  // Copy an environment, optionally into another workspace
+  clone: async (id: string, data: { name?: string; workspace_id?: string } = {}): Promise<Environment> => {
+    const response = await axiosInstance.post(`/environments/${id}/clone`, data);
+    return response.data;
+  },
+
+  // Compare two environments key by key
+  diff: async (id: string, otherId: string): Promise<EnvironmentDiff> => {
+    const response = await axiosInstance.get(`/environments/${id}/diff`, { params: { with: otherId } });
+    return response.data;
+  },
+
+  // Import a .env or JSON file, merging into existing variables unless replace is set
+  import: async (id: string, file: File, replace = false): Promise<Environment> => {
+    const formData = new FormData();
+    formData.append('file', file);
+    const response = await axiosInstance.post(`/environments/${id}/import`, formData, {
+      params: replace ? { mode: 'replace' } : undefined,
+    });
+    return response.data;
+  },
+
+  // Download variables as a .env or JSON file
+  export: async (id: string, format: 'dotenv' | 'json' = 'dotenv', includeSecrets = false): Promise<Blob> => {
+    const response = await axiosInstance.get(`/environments/${id}/export`, {
+      params: { format, include_secrets: includeSecrets || undefined },
+      responseType: 'blob',
+    });
+    return response.data;
+  },
 };
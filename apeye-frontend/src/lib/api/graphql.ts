@@ -0,0 +1,37 @@
+import axiosInstance from './axios';
+import { isPrivateURL } from './requests';
+import type { GraphQLSchema, RequestConfig } from '@/types';
+import { AGENT_BASE_URL } from '@/config/constants';
+import { getStoredAgentToken } from '@/lib/agent-auth';
+
+export const graphqlApi = {
+  // Fetches the endpoint's schema for autocomplete, with the request's headers
+  // and auth. Schemas are cached for 10 minutes unless refresh is set.
+  introspect: async (config: RequestConfig, refresh = false): Promise<GraphQLSchema> => {
+    const params = refresh ? '?refresh=true' : '';
+
+    if (isPrivateURL(config.url)) {
+      const token = getStoredAgentToken();
+      if (!token) {
+        throw new Error('Local agent token is missing. Pair the app with your running agent.');
+      }
+
+      const response = await fetch(`${AGENT_BASE_URL}/graphql/introspect${params}`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'X-APEYE-Agent-Token': token,
+        },
+        body: JSON.stringify(config),
+      });
+      const payload = await response.json().catch(() => null);
+      if (!response.ok) {
+        throw new Error(payload?.error || `Introspection failed (${response.status})`);
+      }
+      return payload;
+    }
+
+    const response = await axiosInstance.post(`/graphql/introspect${params}`, config);
+    return response.data;
+  },
+};
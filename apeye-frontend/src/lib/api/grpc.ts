@@ -0,0 +1,66 @@
+import axiosInstance from './axios';
+import { isPrivateURL } from './requests';
+import type { GrpcResponse, GrpcService, RequestConfig } from '@/types';
+import { AGENT_BASE_URL } from '@/config/constants';
+import { getStoredAgentToken } from '@/lib/agent-auth';
+
+async function postToAgent<T>(path: string, config: RequestConfig): Promise<T> {
+  const token = getStoredAgentToken();
+  if (!token) {
+    throw new Error('Local agent token is missing. Pair the app with your running agent.');
+  }
+
+  const response = await fetch(`${AGENT_BASE_URL}${path}`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+      'X-APEYE-Agent-Token': token,
+    },
+    body: JSON.stringify(config),
+  });
+  const payload = await response.json().catch(() => null);
+  if (!response.ok) {
+    throw new Error(payload?.error || `Local agent request failed (${response.status})`);
+  }
+  return payload;
+}
+
+export const grpcApi = {
+  // Lists services and methods from config.grpc.protoFiles, or server reflection
+  services: async (config: RequestConfig): Promise<GrpcService[]> => {
+    if (isPrivateURL(config.url)) {
+      return postToAgent('/grpc/services', config);
+    }
+    const response = await axiosInstance.post('/grpc/services', config);
+    return response.data;
+  },
+
+  // Calls config.grpc.method; a failed call resolves with its gRPC status
+  execute: async (config: RequestConfig): Promise<GrpcResponse> => {
+    if (isPrivateURL(config.url)) {
+      const response = await postToAgent<GrpcResponse>('/grpc/execute', config);
+
+      fetch('/api/history', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        credentials: 'include',
+        body: JSON.stringify({
+          method: 'GRPC',
+          url: config.url,
+          requestData: config,
+          responseData: response,
+          statusCode: response.status,
+          responseTime: response.time,
+        }),
+      }).catch(() => {
+        // Silently fail - history save is not critical
+      });
+
+      return response;
+    }
+
+    // Backend saves the call to history
+    const response = await axiosInstance.post('/grpc/execute', config);
+    return response.data;
+  },
+};
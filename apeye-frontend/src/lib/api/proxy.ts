@@ -0,0 +1,25 @@
+import { isAxiosError } from 'axios';
+import axiosInstance from './axios';
+import type { ProxyConfig, ProxySetting } from '@/types';
+
+export const proxyApi = {
+  // Resolves to null when the workspace has no proxy
+  get: async (workspaceId: string): Promise<ProxySetting | null> => {
+    try {
+      const response = await axiosInstance.get(`/workspaces/${workspaceId}/proxy`);
+      return response.data;
+    } catch (error: unknown) {
+      if (isAxiosError(error) && error.response?.status === 404) return null;
+      throw error;
+    }
+  },
+
+  update: async (workspaceId: string, proxy: ProxyConfig): Promise<ProxySetting> => {
+    const response = await axiosInstance.put(`/workspaces/${workspaceId}/proxy`, proxy);
+    return response.data;
+  },
+
+  delete: async (workspaceId: string): Promise<void> => {
+    await axiosInstance.delete(`/workspaces/${workspaceId}/proxy`);
+  },
+};
@@ -1,16 +1,35 @@
 import axiosInstance from './axios';
-import { RequestConfig, ApiResponse } from '@/types';
-import { AGENT_BASE_URL } from '@/config/constants';
+import { isAxiosError } from 'axios';
+import { RequestConfig, ApiResponse, Execution, StreamMessage, StreamResult } from '@/types';
+import { AGENT_BASE_URL, API_BASE_URL } from '@/config/constants';
 import { getStoredAgentToken } from '@/lib/agent-auth';
 
+// Hosts the server refused to reach, such as internal names resolving to
+// private addresses; later requests to them go through the local agent
+const agentHosts = new Set<string>();
+
+// Remembers the host of url so its requests run on the local agent
+export function routeToAgent(url: string) {
+  try {
+    agentHosts.add(new URL(url).hostname);
+  } catch {
+    // Not a URL, nothing to remember
+  }
+}
+
+// The server's egress policy blocked the request, see routeToAgent
+export function isEgressDenied(error: unknown): boolean {
+  return isAxiosError(error) && error.response?.status === 403 && !!error.response.data?.useAgent;
+}
+
 // Check if URL points to localhost or private IP
-function isPrivateURL(url: string): boolean {
+export function isPrivateURL(url: string): boolean {
   try {
     const parsed = new URL(url);
     const host = parsed.hostname;
 
-    // Check localhost
-    if (host === 'localhost') return true;
+    // Check localhost and hosts the server refused
+    if (host === 'localhost' || agentHosts.has(host)) return true;
 
     // Check loopback and private IPs
     const ip = host.split('.').map(Number);
@@ -34,6 +53,12 @@ function isPrivateURL(url: string): boolean {
   }
 }
 
+// Requests to private hosts, and bodies read from a local file, run on the
+// local agent
+export function runsOnAgent(config: RequestConfig): boolean {
+  return isPrivateURL(config.url) || (config.body.type === 'binary' && !!config.body.file?.path);
+}
+
 async function executeViaLocalAgent(config: RequestConfig): Promise<ApiResponse> {
   try {
     const token = getStoredAgentToken();
@@ -79,11 +104,55 @@ async function executeViaLocalAgent(config: RequestConfig): Promise<ApiResponse>
   }
 }
 
+// Reads a Server-Sent Events response, passing messages to onMessage, and
+// resolves with the summary from the final "done" event
+async function readEventStream(
+  response: Response,
+  onMessage: (message: StreamMessage) => void
+): Promise<StreamResult> {
+  if (!response.ok || !response.body) {
+    const payload = await response.json().catch(() => null);
+    throw new Error(payload?.error || `Streaming request failed (${response.status})`);
+  }
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+
+  while (true) {
+    const { value, done } = await reader.read();
+    if (done) break;
+    buffer += decoder.decode(value, { stream: true });
+
+    let boundary = buffer.indexOf('\n\n');
+    while (boundary !== -1) {
+      const block = buffer.slice(0, boundary);
+      buffer = buffer.slice(boundary + 2);
+      boundary = buffer.indexOf('\n\n');
+
+      let event = 'message';
+      const data: string[] = [];
+      for (const line of block.split('\n')) {
+        if (line.startsWith('event:')) event = line.slice(6).trim();
+        else if (line.startsWith('data:')) data.push(line.slice(5).replace(/^ /, ''));
+      }
+      if (data.length === 0) continue;
+
+      const payload = JSON.parse(data.join('\n'));
+      if (event === 'done') return payload as StreamResult;
+      if (event === 'error') throw new Error(payload.error || 'Streaming request failed');
+      onMessage(payload as StreamMessage);
+    }
+  }
+
+  throw new Error('Stream ended unexpectedly');
+}
+
 export const requestsApi = {
   // Execute an API request
   execute: async (config: RequestConfig): Promise<ApiResponse> => {
-    // Use local agent for localhost/private URLs
-    if (isPrivateURL(config.url)) {
+    // Use local agent for localhost/private URLs and local files
+    if (runsOnAgent(config)) {
       const response = await executeViaLocalAgent(config);
       
       // Save to history via dedicated API route (not proxy)
@@ -112,6 +181,115 @@ export const requestsApi = {
     return response.data;
   },
 
+  // Execute a request and receive the response as it streams in. Abort the
+  // signal to cancel; the summary is saved to history either way.
+  executeStream: async (
+    config: RequestConfig,
+    onMessage: (message: StreamMessage) => void,
+    signal?: AbortSignal
+  ): Promise<StreamResult> => {
+    if (runsOnAgent(config)) {
+      const token = getStoredAgentToken();
+      if (!token) {
+        throw new Error('Local agent token is missing. Pair the app with your running agent.');
+      }
+
+      const response = await fetch(`${AGENT_BASE_URL}/execute/stream`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'X-APEYE-Agent-Token': token,
+        },
+        body: JSON.stringify(config),
+        signal,
+      });
+      const result = await readEventStream(response, onMessage);
+
+      fetch('/api/history', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        credentials: 'include',
+        body: JSON.stringify({
+          method: config.method,
+          url: config.url,
+          requestData: config,
+          responseData: result,
+          statusCode: result.status,
+          responseTime: result.time,
+        }),
+      }).catch(() => {
+        // Silently fail - history save is not critical
+      });
+
+      return result;
+    }
+
+    // Backend saves the summary to history
+    const response = await fetch(`${API_BASE_URL}/requests/execute/stream`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      credentials: 'include',
+      body: JSON.stringify(config),
+      signal,
+    });
+    return readEventStream(response, onMessage);
+  },
+
+  // Lists requests in flight on the backend, or the local agent
+  executions: async (agent = false): Promise<Execution[]> => {
+    if (agent) {
+      const token = getStoredAgentToken();
+      const response = await fetch(`${AGENT_BASE_URL}/executions`, {
+        headers: { 'X-APEYE-Agent-Token': token || '' },
+      });
+      return response.ok ? response.json() : [];
+    }
+    const response = await axiosInstance.get('/requests/executions');
+    return response.data;
+  },
+
+  // Cancels a request in flight by the executionId it was sent with. Resolves
+  // to false when it had already finished.
+  cancel: async (config: RequestConfig): Promise<boolean> => {
+    if (!config.executionId) return false;
+    const path = `/executions/${encodeURIComponent(config.executionId)}/cancel`;
+
+    if (runsOnAgent(config)) {
+      const token = getStoredAgentToken();
+      const response = await fetch(`${AGENT_BASE_URL}${path}`, {
+        method: 'POST',
+        headers: { 'X-APEYE-Agent-Token': token || '' },
+      });
+      return response.ok;
+    }
+
+    try {
+      await axiosInstance.post(`/requests${path}`);
+      return true;
+    } catch {
+      return false;
+    }
+  },
+
+  // Downloads the full body of a truncated response from wherever it was
+  // executed. Bodies are kept for 15 minutes.
+  downloadBody: async (bodyId: string, agent = false): Promise<Blob> => {
+    const path = `/bodies/${encodeURIComponent(bodyId)}`;
+    if (agent) {
+      const token = getStoredAgentToken();
+      const response = await fetch(`${AGENT_BASE_URL}${path}`, {
+        headers: { 'X-APEYE-Agent-Token': token || '' },
+      });
+      if (!response.ok) {
+        const payload = await response.json().catch(() => null);
+        throw new Error(payload?.error || `Download failed (${response.status})`);
+      }
+      return response.blob();
+    }
+    const response = await axiosInstance.get(`/requests${path}`, { responseType: 'blob' });
+    return response.data;
+  },
+
   // Future: Save request to collection
   // save: async (collectionId: string, request: SavedRequest) => { ... },
 };
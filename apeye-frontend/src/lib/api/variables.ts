@@ -0,0 +1,43 @@
+import axiosInstance from './axios';
+import type { EnvironmentVariable, RequestConfig, ResolvedVariable, VariableSet } from '@/types';
+
+export const variablesApi = {
+  // Current user's global variables
+  getGlobal: async (): Promise<VariableSet> => {
+    const response = await axiosInstance.get('/variables/global');
+    return response.data;
+  },
+
+  updateGlobal: async (variables: EnvironmentVariable[]): Promise<VariableSet> => {
+    const response = await axiosInstance.put('/variables/global', { variables });
+    return response.data;
+  },
+
+  // Workspace-level globals
+  getWorkspace: async (workspaceId: string): Promise<VariableSet> => {
+    const response = await axiosInstance.get(`/workspaces/${workspaceId}/variables`);
+    return response.data;
+  },
+
+  updateWorkspace: async (workspaceId: string, variables: EnvironmentVariable[]): Promise<VariableSet> => {
+    const response = await axiosInstance.put(`/workspaces/${workspaceId}/variables`, { variables });
+    return response.data;
+  },
+
+  // Collection variables
+  getCollection: async (collectionId: string): Promise<VariableSet> => {
+    const response = await axiosInstance.get(`/collections/${collectionId}/variables`);
+    return response.data;
+  },
+
+  updateCollection: async (collectionId: string, variables: EnvironmentVariable[]): Promise<VariableSet> => {
+    const response = await axiosInstance.put(`/collections/${collectionId}/variables`, { variables });
+    return response.data;
+  },
+
+  // Resolved value and source scope of every placeholder in a request
+  preview: async (config: RequestConfig): Promise<ResolvedVariable[]> => {
+    const response = await axiosInstance.post('/variables/preview', config);
+    return response.data.variables;
+  },
+};
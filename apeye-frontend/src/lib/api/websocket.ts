@@ -0,0 +1,79 @@
+import axiosInstance from './axios';
+import { isPrivateURL } from './requests';
+import type { RequestConfig, WebSocketCommand, WebSocketEvent } from '@/types';
+import { AGENT_BASE_URL, WEBSOCKET_BASE_URL } from '@/config/constants';
+import { getStoredAgentToken } from '@/lib/agent-auth';
+
+export interface WebSocketConnection {
+  send: (command: WebSocketCommand) => void;
+  close: (code?: number, reason?: string) => void;
+}
+
+// Saves the transcript of an agent session; the backend saves its own
+function saveTranscript(config: RequestConfig, event: WebSocketEvent) {
+  if (event.event !== 'closed' || !event.transcript) return;
+
+  fetch('/api/history', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    credentials: 'include',
+    body: JSON.stringify({
+      method: 'GET',
+      url: config.url,
+      requestData: config,
+      responseData: event.transcript,
+      statusCode: event.transcript.status,
+      responseTime: event.transcript.duration,
+    }),
+  }).catch(() => {
+    // Silently fail - history save is not critical
+  });
+}
+
+export const websocketApi = {
+  // Opens a WebSocket to config.url through the backend, or through the local
+  // agent for localhost/private URLs. Frames arrive as events; the "closed"
+  // event carries the transcript, which is saved to history.
+  connect: async (
+    config: RequestConfig,
+    onEvent: (event: WebSocketEvent) => void
+  ): Promise<WebSocketConnection> => {
+    const agent = isPrivateURL(config.url);
+    let socket: WebSocket;
+
+    if (agent) {
+      const token = getStoredAgentToken();
+      if (!token) {
+        throw new Error('Local agent token is missing. Pair the app with your running agent.');
+      }
+
+      // The token goes in the first message so it stays out of the agent's logs
+      const agentURL = AGENT_BASE_URL.replace(/^http/, 'ws');
+      socket = new WebSocket(`${agentURL}/ws`);
+      socket.addEventListener('open', () => {
+        socket.send(JSON.stringify({ action: 'connect', token, config }));
+      });
+    } else {
+      const response = await axiosInstance.post('/websocket/sessions', config);
+      socket = new WebSocket(`${WEBSOCKET_BASE_URL}${response.data.path}`);
+    }
+
+    socket.addEventListener('message', (message) => {
+      const event = JSON.parse(message.data) as WebSocketEvent;
+      if (agent) saveTranscript(config, event);
+      onEvent(event);
+    });
+    socket.addEventListener('error', () => {
+      onEvent({ event: 'error', error: 'WebSocket connection failed' });
+    });
+
+    return {
+      send: (command) => socket.send(JSON.stringify(command)),
+      close: (code, reason) => {
+        if (socket.readyState === WebSocket.OPEN) {
+          socket.send(JSON.stringify({ action: 'close', code, reason }));
+        }
+      },
+    };
+  },
+};
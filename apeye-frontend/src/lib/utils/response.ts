@@ -47,8 +47,8 @@ export function formatBytes(bytes: number): string {
     return navigator.clipboard.writeText(text);
   }
   
-  export function downloadFile(content: string, filename: string, contentType: string) {
-    const blob = new Blob([content], { type: contentType });
+  export function downloadFile(content: string | Blob, filename: string, contentType: string) {
+    const blob = content instanceof Blob ? content : new Blob([content], { type: contentType });
     const url = URL.createObjectURL(blob);
     const link = document.createElement('a');
     link.href = url;
@@ -1,4 +1,18 @@
-import type { RequestConfig, KeyValue } from '@/types';
+import type { RequestConfig, KeyValue, Environment } from '@/types';
+
+/**
+ * Returns the enabled variables of an environment as a name -> value map
+ * Secret values are masked in the browser, so they are left for the backend to resolve
+ */
+export function environmentValues(environment: Environment | null): Record<string, string> {
+  const values: Record<string, string> = {};
+  environment?.variables?.forEach((variable) => {
+    if (variable.enabled && variable.type !== 'secret') {
+      values[variable.key] = variable.value;
+    }
+  });
+  return values;
+}
 
 /**
  * Resolves {{variable}} placeholders in a string using the provided variables map
@@ -80,6 +94,15 @@ export function resolveConfigVariables(
       formData: config.body.formData
         ? resolveKeyValueArray(config.body.formData, variables)
         : config.body.formData,
+      graphql: config.body.graphql
+        ? {
+            query: resolveVariablesInString(config.body.graphql.query, variables),
+            variables: config.body.graphql.variables
+              ? resolveVariablesInString(config.body.graphql.variables, variables)
+              : config.body.graphql.variables,
+            operationName: config.body.graphql.operationName,
+          }
+        : config.body.graphql,
     },
   };
 }
@@ -119,6 +142,10 @@ export function extractVariablesFromConfig(config: RequestConfig): string[] {
   if (config.body.formData) {
     extractFromKeyValues(config.body.formData);
   }
+  if (config.body.graphql) {
+    extractFromString(config.body.graphql.query);
+    extractFromString(config.body.graphql.variables || '');
+  }
 
   return Array.from(variableSet);
 }
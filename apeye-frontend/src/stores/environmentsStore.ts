@@ -1,7 +1,8 @@
 import { create } from 'zustand';
 import { persist } from 'zustand/middleware';
 import { environmentsApi } from '@/lib/api/environments';
-import type { Environment } from '@/types';
+import type { Environment, EnvironmentVariable } from '@/types';
+import { environmentValues } from '@/lib/variables';
 import toast from 'react-hot-toast';
 
 interface EnvironmentsState {
@@ -11,8 +12,8 @@ interface EnvironmentsState {
 
   // Actions
   fetchEnvironments: () => Promise<void>;
-  createEnvironment: (name: string, variables?: Record<string, string>) => Promise<Environment>;
-  updateEnvironment: (id: string, data: { name?: string; variables?: Record<string, string> }) => Promise<void>;
+  createEnvironment: (name: string, variables?: EnvironmentVariable[]) => Promise<Environment>;
+  updateEnvironment: (id: string, data: { name?: string; variables?: EnvironmentVariable[] }) => Promise<void>;
   deleteEnvironment: (id: string) => Promise<void>;
   setActiveEnvironment: (id: string | null) => void;
 
@@ -39,7 +40,7 @@ export const useEnvironmentsStore = create<EnvironmentsState>()(
         }
       },
 
-      createEnvironment: async (name: string, variables: Record<string, string> = {}) => {
+      createEnvironment: async (name: string, variables: EnvironmentVariable[] = []) => {
         set({ isLoading: true });
         try {
           const newEnvironment = await environmentsApi.create({ name, variables });
@@ -56,7 +57,7 @@ export const useEnvironmentsStore = create<EnvironmentsState>()(
         }
       },
 
-      updateEnvironment: async (id: string, data: { name?: string; variables?: Record<string, string> }) => {
+      updateEnvironment: async (id: string, data: { name?: string; variables?: EnvironmentVariable[] }) => {
         try {
           const updatedEnvironment = await environmentsApi.update(id, data);
           set((state) => ({
@@ -99,8 +100,9 @@ export const useEnvironmentsStore = create<EnvironmentsState>()(
         if (!activeEnv || !text) return text;
 
         // Replace {{variable}} with value from active environment
+        const values = environmentValues(activeEnv);
         return text.replace(/\{\{(\w+)\}\}/g, (match, varName) => {
-          const value = activeEnv.variables[varName];
+          const value = values[varName];
           return value !== undefined ? value : match;
         });
       },
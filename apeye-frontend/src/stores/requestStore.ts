@@ -1,13 +1,18 @@
 import { create } from 'zustand';
-import { RequestConfig, ApiResponse, KeyValue, SavedRequest } from '@/types';
+import { RequestConfig, ApiResponse, KeyValue, SavedRequest, ApiKeyLocation, GraphQLBody, BodyFile } from '@/types';
 import { nanoid } from 'nanoid';
-import { requestsApi } from '@/lib/api/requests';
-import { resolveConfigVariables } from '@/lib/variables';
+import { isAxiosError } from 'axios';
+import { requestsApi, isEgressDenied, isPrivateURL, routeToAgent } from '@/lib/api/requests';
+import { resolveConfigVariables, environmentValues } from '@/lib/variables';
 import { useEnvironmentsStore } from './environmentsStore';
 import toast from 'react-hot-toast';
 import { useAgentStore } from './agentStore';
 
 function isLocalOrPrivateURL(url: string): boolean {
+  if (isPrivateURL(url)) {
+    return true;
+  }
+
   try {
     const parsed = new URL(url);
     const host = parsed.hostname;
@@ -31,6 +36,10 @@ function isLocalOrPrivateURL(url: string): boolean {
 }
 
 function getErrorMessage(error: unknown): string {
+  if (isAxiosError(error) && error.response?.data?.error) {
+    return error.response.data.error;
+  }
+
   if (error instanceof Error) {
     return error.message;
   }
@@ -45,11 +54,21 @@ function getErrorMessage(error: unknown): string {
   return 'Request failed';
 }
 
+// Cancelled executions come back as 499 from the backend, or as the agent's error message
+function isCancelledError(error: unknown): boolean {
+  if (isAxiosError(error)) {
+    return error.response?.status === 499;
+  }
+  return error instanceof Error && error.message === 'Request cancelled';
+}
+
 interface RequestState {
   config: RequestConfig;
   response: ApiResponse | null;
   isLoading: boolean;
   showAgentSetupDialog: boolean;
+  // Config of the request in flight, with its executionId
+  inFlight: RequestConfig | null;
   
   setMethod: (method: RequestConfig['method']) => void;
   setUrl: (url: string) => void;
@@ -66,15 +85,19 @@ interface RequestState {
   setAuthToken: (token: string) => void;
   setAuthBasic: (username: string, password: string) => void;
   setAuthApiKey: (apiKey: string, apiValue: string) => void;
+  setAuthApiKeyLocation: (addTo: ApiKeyLocation) => void;
   
   setBodyType: (type: RequestConfig['body']['type']) => void;
   setBodyContent: (content: string) => void;
+  setGraphQL: (field: keyof GraphQLBody, value: string) => void;
+  setBodyFile: (file: BodyFile | undefined) => void;
   
   addFormData: () => void;
   updateFormData: (id: string, field: keyof KeyValue, value: string | boolean) => void;
   removeFormData: (id: string) => void;
   
   executeRequest: () => Promise<void>;
+  cancelRequest: () => Promise<void>;
   setResponse: (response: ApiResponse | null) => void;
   setLoading: (loading: boolean) => void;
   setShowAgentSetupDialog: (open: boolean) => void;
@@ -103,6 +126,7 @@ export const useRequestStore = create<RequestState>((set, get) => ({
   config: initialConfig,
   response: null,
   isLoading: false,
+  inFlight: null,
   showAgentSetupDialog: false,
 
   setMethod: (method) => set((state) => ({
@@ -190,6 +214,13 @@ export const useRequestStore = create<RequestState>((set, get) => ({
     },
   })),
 
+  setAuthApiKeyLocation: (addTo) => set((state) => ({
+    config: {
+      ...state.config,
+      auth: { ...state.config.auth, addTo },
+    },
+  })),
+
   // Body
   setBodyType: (type) => set((state) => ({
     config: {
@@ -205,6 +236,23 @@ export const useRequestStore = create<RequestState>((set, get) => ({
     },
   })),
 
+  setGraphQL: (field, value) => set((state) => ({
+    config: {
+      ...state.config,
+      body: {
+        ...state.config.body,
+        graphql: { query: '', ...state.config.body.graphql, [field]: value },
+      },
+    },
+  })),
+
+  setBodyFile: (file) => set((state) => ({
+    config: {
+      ...state.config,
+      body: { ...state.config.body, file },
+    },
+  })),
+
   // Form Data
   addFormData: () => set((state) => ({
     config: {
@@ -273,14 +321,34 @@ export const useRequestStore = create<RequestState>((set, get) => ({
 
     try {
       // Get active environment variables and resolve them in the config
+      // Secret values arrive masked, so their placeholders are left for the backend
       const activeEnv = useEnvironmentsStore.getState().getActiveEnvironment();
-      const variables = activeEnv?.variables || {};
-      const resolvedConfig = resolveConfigVariables(config, variables);
+      const variables = environmentValues(activeEnv);
+      const resolvedConfig = {
+        ...resolveConfigVariables(config, variables),
+        environmentId: activeEnv?.id,
+        scopes: activeEnv ? [{ scope: 'environment' as const, values: variables }] : undefined,
+        executionId: nanoid(),
+      };
+      set({ inFlight: resolvedConfig });
 
       const response = await requestsApi.execute(resolvedConfig);
-      set({ response, isLoading: false });
+      set({ response, isLoading: false, inFlight: null });
       toast.success('Request completed');
     } catch (error: unknown) {
+      set({ inFlight: null });
+      if (isCancelledError(error)) {
+        set({ isLoading: false });
+        toast('Request cancelled');
+        return;
+      }
+      if (isEgressDenied(error)) {
+        // Private and internal hosts are only reachable from the user's machine
+        routeToAgent(config.url);
+        set({ isLoading: false });
+        toast.error(`${getErrorMessage(error)} Send the request again to run it on the local agent.`, { duration: 6000 });
+        return;
+      }
       const errorMessage = getErrorMessage(error);
       const isLocalhost = isLocalOrPrivateURL(config.url);
       
@@ -331,6 +399,15 @@ export const useRequestStore = create<RequestState>((set, get) => ({
     }
   },
 
+  cancelRequest: async () => {
+    const { inFlight } = get();
+    if (!inFlight) return;
+    const cancelled = await requestsApi.cancel(inFlight);
+    if (!cancelled) {
+      toast.error('The request has already finished');
+    }
+  },
+
   setResponse: (response) => set({ response }),
   setLoading: (loading) => set({ isLoading: loading }),
   setShowAgentSetupDialog: (open) => set({ showAgentSetupDialog: open }),
@@ -1,8 +1,80 @@
 export type HttpMethod = 'GET' | 'POST' | 'PUT' | 'DELETE' | 'PATCH' | 'HEAD' | 'OPTIONS';
 
-export type AuthType = 'none' | 'bearer' | 'basic' | 'api-key';
+export type AuthType = 'none' | 'bearer' | 'basic' | 'api-key' | 'oauth2' | 'aws-sigv4' | 'digest' | 'hmac';
 
-export type BodyType = 'none' | 'json' | 'form-data' | 'x-www-form-urlencoded' | 'raw';
+export type ApiKeyLocation = 'header' | 'query' | 'cookie';
+
+export type OAuth2GrantType = 'client_credentials' | 'password' | 'refresh_token' | 'authorization_code';
+
+export interface OAuth2Config {
+  grantType: OAuth2GrantType;
+  tokenUrl: string;
+  clientId: string;
+  clientSecret?: string;
+  scope?: string;
+  audience?: string;
+  clientAuth?: 'header' | 'body';
+  username?: string;
+  password?: string;
+  refreshToken?: string;
+  authUrl?: string;
+  redirectUri?: string;
+  code?: string;
+  codeVerifier?: string;
+}
+
+export interface AwsSigV4Config {
+  accessKeyId: string;
+  secretAccessKey: string;
+  sessionToken?: string;
+  region: string;
+  service: string;
+}
+
+export interface HmacConfig {
+  algorithm: 'sha1' | 'sha256' | 'sha384' | 'sha512';
+  keyId?: string;
+  secret: string;
+  signedHeaders?: string[];
+  headerName?: string;
+  headerTemplate?: string;
+  encoding?: 'base64' | 'hex';
+}
+
+export type BodyType =
+  | 'none'
+  | 'json'
+  | 'form-data'
+  | 'x-www-form-urlencoded'
+  | 'raw'
+  | 'graphql'
+  | 'xml'
+  | 'html'
+  | 'yaml'
+  | 'msgpack' // written as JSON, sent as MessagePack
+  | 'binary'; // base64 content, sent as bytes
+
+// Sent as a JSON POST, or in the query string for GET requests
+export interface GraphQLBody {
+  query: string;
+  variables?: string; // JSON object as text, may contain {{variables}}
+  operationName?: string;
+}
+
+export interface GraphQLError {
+  message: string;
+  locations?: { line: number; column: number }[];
+  path?: (string | number)[];
+  extensions?: Record<string, any>;
+}
+
+// Introspection result; schema is data.__schema, cached for 10 minutes
+export interface GraphQLSchema {
+  url: string;
+  schema: any;
+  fetchedAt: string;
+  cached: boolean;
+}
 
 export interface KeyValue {
   id: string;
@@ -23,12 +95,182 @@ export interface RequestConfig {
     password?: string;
     apiKey?: string;
     apiValue?: string;
+    addTo?: ApiKeyLocation;
+    oauth2?: OAuth2Config;
+    aws?: AwsSigV4Config;
+    hmac?: HmacConfig;
   };
   body: {
     type: BodyType;
     content: string;
     formData?: KeyValue[];
+    graphql?: GraphQLBody;
+    file?: BodyFile; // binary bodies sent from a file instead of base64 content
   };
+  // Variable scopes. The backend loads these itself, secrets included;
+  // the local agent only sees the non-secret values sent in `scopes`
+  workspaceId?: string;
+  collectionId?: string;
+  requestId?: string;
+  environmentId?: string;
+  variables?: KeyValue[]; // per-request overrides
+  scopes?: VariableLayer[];
+  // Client certificates for the local agent, which can also read PEM files
+  // on disk. The backend only uses certificates uploaded to /certificates.
+  certificates?: ClientCertificateConfig[];
+  // Overrides the workspace proxy
+  proxy?: ProxyConfig;
+  // Send and capture cookies with the workspace cookie jar
+  useCookieJar?: boolean;
+  // Makes this a gRPC call to a grpc:// or grpcs:// URL; headers and auth are
+  // sent as metadata and body.content holds the JSON request message
+  grpc?: GrpcConfig;
+  // Identifies the execution while in flight so it can be cancelled
+  executionId?: string;
+  // Return the body without undoing its Content-Encoding (base64 encoded)
+  rawResponse?: boolean;
+}
+
+// A request in flight
+export interface Execution {
+  id: string;
+  method: string;
+  url: string;
+  startedAt: string;
+}
+
+export interface GrpcConfig {
+  service: string; // fully qualified, e.g. "helloworld.Greeter"
+  method: string;
+  protoFiles?: ProtoFile[]; // without these the server's reflection is used
+}
+
+// An uploaded .proto file; name is the path other files import it by
+export interface ProtoFile {
+  name: string;
+  content: string;
+}
+
+export interface GrpcMethod {
+  name: string;
+  fullName: string;
+  inputType: string;
+  outputType: string;
+  clientStreaming: boolean;
+  serverStreaming: boolean;
+  example: string; // request message with zero values
+}
+
+export interface GrpcService {
+  name: string;
+  methods: GrpcMethod[];
+}
+
+export interface GrpcResponse {
+  status: number; // gRPC status code, 0 is OK
+  statusText: string;
+  message?: string;
+  details?: { typeUrl: string; value: string }[];
+  headers: Record<string, string>;
+  trailers: Record<string, string>;
+  messages: any[]; // one per response, several for server streaming
+  truncated: boolean; // stopped after 1000 messages
+  time: number;
+  size: number;
+}
+
+// The file of a binary body: a blob uploaded to the workspace, or a path the
+// local agent reads. Either is streamed rather than loaded into memory.
+export interface BodyFile {
+  blobId?: string;
+  path?: string;
+  name?: string;
+  contentType?: string;
+}
+
+// A file uploaded for binary request bodies
+export interface UploadedBlob {
+  id: string;
+  workspace_id: string;
+  userId: string;
+  name: string;
+  contentType: string;
+  size: number;
+  sha256: string;
+  created_at: string;
+}
+
+export interface Cookie {
+  id: string;
+  workspace_id: string;
+  name: string;
+  value: string;
+  domain: string;
+  path: string;
+  expires?: string; // session cookie when missing
+  secure: boolean;
+  httpOnly: boolean;
+  hostOnly: boolean;
+  sameSite?: 'Lax' | 'Strict' | 'None';
+  created_at: string;
+  updated_at: string;
+}
+
+export type CookieInput = Omit<Cookie, 'id' | 'workspace_id' | 'created_at' | 'updated_at'>;
+
+export interface ProxyConfig {
+  url: string; // http://, https:// or socks5://
+  username?: string;
+  password?: string;
+  bypass?: string[]; // "localhost", "*.corp", ".corp" or CIDR ranges
+}
+
+// A workspace proxy; the password comes back masked
+export interface ProxySetting extends ProxyConfig {
+  id: string;
+  workspace_id: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ClientCertificateConfig {
+  hostPattern: string;
+  certificate?: string;
+  privateKey?: string;
+  ca?: string;
+  certificateFile?: string;
+  privateKeyFile?: string;
+  caFile?: string;
+}
+
+// An uploaded client certificate; the private key is never returned
+export interface ClientCertificate {
+  id: string;
+  name: string;
+  hostPattern: string;
+  certificate?: string;
+  ca?: string;
+  subject?: string;
+  expiresAt?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+// Lowest to highest precedence: global < workspace < collection < environment < request
+export type VariableScope = 'global' | 'workspace' | 'collection' | 'environment' | 'request';
+
+export interface VariableLayer {
+  scope: VariableScope;
+  values: Record<string, string>;
+  secrets?: string[];
+}
+
+export interface ResolvedVariable {
+  name: string;
+  value: string;
+  source?: VariableScope;
+  secret: boolean;
+  resolved: boolean;
 }
 
 export interface ApiResponse {
@@ -37,7 +279,75 @@ export interface ApiResponse {
   headers: Record<string, string>;
   data: any;
   time: number;
-  size: number;
+  compressedSize: number; // bytes received
+  decompressedSize: number; // bytes after undoing contentEncoding, 0 for raw responses
+  contentEncoding?: string;
+  charset?: string; // the text body was converted to UTF-8 from this charset
+  dataEncoding?: 'base64'; // data holds the raw compressed bytes
+  graphqlErrors?: GraphQLError[]; // errors array of a GraphQL response
+  executionId?: string;
+  truncated?: boolean; // body too large to inline; data is a preview of its start
+  bodyId?: string; // downloads the full body of a truncated response
+}
+
+// Sent while a streamed response arrives
+export interface StreamMessage {
+  type: 'response' | 'chunk' | 'event';
+  time: number;
+  status?: number;
+  statusText?: string;
+  headers?: Record<string, string>;
+  data?: string;
+  encoding?: 'base64'; // data holds a chunk that is not UTF-8 text
+  event?: string; // SSE event name
+  id?: string; // SSE event id
+}
+
+// Summary of a streamed response; data holds the body as text, cut off at 1MB
+export interface StreamResult extends ApiResponse {
+  chunks: number;
+  events: number;
+  truncated: boolean;
+  cancelled: boolean;
+  error?: string;
+}
+
+export type WebSocketFrameType = 'text' | 'binary' | 'ping' | 'pong' | 'close';
+
+// One frame of a WebSocket session; binary data is base64 encoded
+export interface WebSocketFrame {
+  direction: 'sent' | 'received';
+  type: WebSocketFrameType;
+  data?: string;
+  code?: number; // close code
+  time: number; // ms since the connection opened
+}
+
+export interface WebSocketTranscript {
+  status: number;
+  headers: Record<string, string>;
+  protocol?: string;
+  frames: WebSocketFrame[];
+  truncated: boolean; // more than 1000 frames
+  duration: number;
+  error?: string;
+}
+
+// Sent by the browser over the relay connection
+export interface WebSocketCommand {
+  action: 'send' | 'ping' | 'close';
+  type?: 'text' | 'binary';
+  data?: string;
+  code?: number;
+  reason?: string;
+}
+
+// Received from the relay connection
+export interface WebSocketEvent {
+  event: 'open' | 'frame' | 'closed' | 'error';
+  frame?: WebSocketFrame;
+  transcript?: WebSocketTranscript; // handshake on "open", everything on "closed"
+  error?: string;
 }
 
 export interface User {
@@ -75,19 +385,49 @@ export interface SavedRequest {
   updated_at: string;
 }
 
+export type VariableType = 'string' | 'secret' | 'number' | 'boolean' | 'json';
+
+export interface EnvironmentVariable {
+  key: string;
+  value: string; // secret values come back masked
+  type: VariableType;
+  description: string;
+  enabled: boolean;
+}
+
+export interface VariableSet {
+  id: string;
+  scope: 'global' | 'workspace' | 'collection';
+  variables: EnvironmentVariable[];
+  created_at: string;
+  updated_at: string;
+}
+
 export interface Environment {
   id: string;
   workspace_id: string;
   name: string;
-  variables: Record<string, string>;
+  variables: EnvironmentVariable[];
   created_at: string;
   updated_at: string;
 }
 
+export interface EnvironmentDiff {
+  from: string;
+  to: string;
+  changes: {
+    key: string;
+    status: 'added' | 'removed' | 'changed' | 'unchanged';
+    before?: EnvironmentVariable;
+    after?: EnvironmentVariable;
+  }[];
+}
+
 export interface Workspace {
   id: string;
   userId: string;
   name: string;
+  personal: boolean;
   createdAt: string;
   updatedAt: string;
 }